@@ -5,7 +5,10 @@ import (
     "net/http"
     "backend/internal/config"
     "backend/internal/database"
+    "backend/internal/mailer"
     "backend/internal/routes"
+    "backend/internal/services"
+    "backend/internal/signing"
     "os"
 
     "github.com/gin-contrib/cors"
@@ -21,6 +24,9 @@ func main() {
 
     // Load configuration
     cfg := config.Load()
+    if err := cfg.Validate(); err != nil {
+        log.Fatal("Invalid configuration: ", err)
+    }
 
     // Initialize database
     db, err := database.Init(cfg.Database)
@@ -29,11 +35,26 @@ func main() {
     }
     defer db.Close()
 
+    // Initialize mailer
+    mail, err := mailer.New(cfg.Mail)
+    if err != nil {
+        log.Fatal("Failed to initialize mailer:", err)
+    }
+
+    // Load token signing keys
+    keys, err := signing.Load(cfg.JWT)
+    if err != nil {
+        log.Fatal("Failed to load JWT signing keys:", err)
+    }
+
     // Set Gin mode
     gin.SetMode(cfg.Server.Mode)
 
     // Create Gin router
     router := gin.Default()
+    if err := router.SetTrustedProxies(cfg.Server.TrustedProxies); err != nil {
+        log.Fatal("Invalid TRUSTED_PROXIES:", err)
+    }
 
     // CORS middleware
     corsConfig := cors.Config{
@@ -59,7 +80,10 @@ func main() {
     })
 
     // Setup routes
-    routes.SetupRoutes(router, db, cfg)
+    routes.SetupRoutes(router, db, cfg, mail, keys)
+
+    // Purge projects that stayed in the trash past the retention period
+    go services.NewProjectService(db, cfg).PurgeTrashPeriodically()
 
     // Create upload directory if it doesn't exist
     if err := os.MkdirAll(cfg.Upload.Path, 0755); err != nil {
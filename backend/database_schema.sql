@@ -7,10 +7,19 @@ ALTER TABLE projects MODIFY cover_image TEXT;;
 -- Users table
 CREATE TABLE users (
     id INT PRIMARY KEY AUTO_INCREMENT,
-    user_name VARCHAR(255) NOT NULL,
+    username VARCHAR(30) UNIQUE NOT NULL, -- URL-safe handle used in profile links
+    user_name VARCHAR(255) NOT NULL, -- display name
     email VARCHAR(255) UNIQUE NOT NULL,
-    password_hash VARCHAR(255) NOT NULL,
+    password_hash VARCHAR(255) NOT NULL, -- empty for accounts created through an identity provider
+    role ENUM('user', 'moderator', 'admin') NOT NULL DEFAULT 'user',
+    suspended_at TIMESTAMP NULL DEFAULT NULL,
+    suspension_reason VARCHAR(255) NULL,
     profile_image TEXT,
+    bio TEXT,
+    email_verified_at TIMESTAMP NULL DEFAULT NULL,
+    totp_secret VARCHAR(64) NULL, -- pending until totp_enabled_at is set
+    totp_enabled_at TIMESTAMP NULL DEFAULT NULL,
+    totp_last_step BIGINT NOT NULL DEFAULT 0, -- last accepted TOTP time step, prevents code replay
     created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
     updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP
 );
@@ -23,13 +32,151 @@ CREATE TABLE projects (
     description TEXT,
     cover_image TEXT,
     project_data JSON, -- Store the entire diagram data as JSON
+    visibility ENUM('private', 'unlisted', 'public') NOT NULL DEFAULT 'private',
+    genre ENUM('fantasy', 'romance', 'sci-fi', 'mystery', 'thriller', 'horror', 'historical', 'adventure', 'drama', 'comedy', 'young-adult', 'other') NULL,
+    forked_from INT NULL, -- the project this one was copied from
+    unpublished_at TIMESTAMP NULL DEFAULT NULL, -- taken down by a moderator, only the owner can still see it
+    unpublish_reason VARCHAR(255) NULL,
+    picked_at TIMESTAMP NULL DEFAULT NULL, -- editor pick, ranked first on the featured list
+    deleted_at TIMESTAMP NULL DEFAULT NULL, -- in the trash until purged
     created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
     updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
     FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    FOREIGN KEY (forked_from) REFERENCES projects(id) ON DELETE SET NULL,
     INDEX idx_user_id (user_id),
+    INDEX idx_visibility (visibility),
+    INDEX idx_deleted_at (deleted_at),
+    INDEX idx_genre (genre),
     INDEX idx_created_at (created_at)
 );
 
+-- Project members (the creator in projects.user_id is always an owner)
+CREATE TABLE project_members (
+    id INT PRIMARY KEY AUTO_INCREMENT,
+    project_id INT NOT NULL,
+    user_id INT NOT NULL,
+    role ENUM('viewer', 'editor', 'owner') NOT NULL DEFAULT 'viewer',
+    invited_by INT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (project_id) REFERENCES projects(id) ON DELETE CASCADE,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    FOREIGN KEY (invited_by) REFERENCES users(id) ON DELETE SET NULL,
+    UNIQUE KEY unique_project_member (project_id, user_id),
+    INDEX idx_user_id (user_id)
+);
+
+-- Secret read-only links to projects
+CREATE TABLE project_share_links (
+    id INT PRIMARY KEY AUTO_INCREMENT,
+    project_id INT NOT NULL,
+    created_by INT NULL,
+    label VARCHAR(100) NOT NULL DEFAULT '',
+    token_hash CHAR(64) NOT NULL UNIQUE, -- SHA-256 of the token
+    password_hash VARCHAR(255) NULL,
+    expires_at TIMESTAMP NULL DEFAULT NULL,
+    revoked_at TIMESTAMP NULL DEFAULT NULL,
+    open_count INT NOT NULL DEFAULT 0,
+    last_opened_at TIMESTAMP NULL DEFAULT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (project_id) REFERENCES projects(id) ON DELETE CASCADE,
+    FOREIGN KEY (created_by) REFERENCES users(id) ON DELETE SET NULL,
+    INDEX idx_project_id (project_id)
+);
+
+-- Stars users give to projects
+CREATE TABLE project_stars (
+    user_id INT NOT NULL,
+    project_id INT NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (user_id, project_id),
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    FOREIGN KEY (project_id) REFERENCES projects(id) ON DELETE CASCADE,
+    INDEX idx_project_id (project_id)
+);
+
+-- Comment threads on projects, optionally anchored to a diagram element
+CREATE TABLE project_comments (
+    id INT PRIMARY KEY AUTO_INCREMENT,
+    project_id INT NOT NULL,
+    user_id INT NOT NULL,
+    parent_id INT NULL, -- the comment this one replies to
+    element_id VARCHAR(64) NULL, -- an element ID from project_data, which may since have been removed
+    body TEXT NOT NULL,
+    edited_at TIMESTAMP NULL DEFAULT NULL,
+    deleted_at TIMESTAMP NULL DEFAULT NULL, -- kept as a placeholder while it has replies
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (project_id) REFERENCES projects(id) ON DELETE CASCADE,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    FOREIGN KEY (parent_id) REFERENCES project_comments(id) ON DELETE CASCADE,
+    INDEX idx_project_created (project_id, created_at)
+);
+
+-- Daily view counts of public projects, for the featured ranking
+CREATE TABLE project_views (
+    project_id INT NOT NULL,
+    day DATE NOT NULL,
+    views INT NOT NULL DEFAULT 0,
+    PRIMARY KEY (project_id, day),
+    FOREIGN KEY (project_id) REFERENCES projects(id) ON DELETE CASCADE
+);
+
+-- The day each viewer last counted as a view of a project, so a viewer adds
+-- at most one view per project per day. visitor is an HMAC of the user ID or
+-- client IP, never the raw address.
+CREATE TABLE project_view_visitors (
+    project_id INT NOT NULL,
+    visitor CHAR(64) NOT NULL,
+    day DATE NOT NULL,
+    PRIMARY KEY (project_id, visitor),
+    FOREIGN KEY (project_id) REFERENCES projects(id) ON DELETE CASCADE
+);
+
+-- Free-form project tags, stored lowercase with hyphens
+CREATE TABLE project_tags (
+    project_id INT NOT NULL,
+    tag VARCHAR(30) NOT NULL,
+    PRIMARY KEY (project_id, tag),
+    FOREIGN KEY (project_id) REFERENCES projects(id) ON DELETE CASCADE,
+    INDEX idx_tag (tag)
+);
+
+-- Folders users organise their projects in
+CREATE TABLE folders (
+    id INT PRIMARY KEY AUTO_INCREMENT,
+    user_id INT NOT NULL,
+    parent_id INT NULL,
+    name VARCHAR(100) NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    FOREIGN KEY (parent_id) REFERENCES folders(id) ON DELETE SET NULL,
+    INDEX idx_user_id (user_id)
+);
+
+-- Which folder each user filed a project in (at most one per user)
+CREATE TABLE folder_projects (
+    user_id INT NOT NULL,
+    project_id INT NOT NULL,
+    folder_id INT NOT NULL,
+    PRIMARY KEY (user_id, project_id),
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    FOREIGN KEY (project_id) REFERENCES projects(id) ON DELETE CASCADE,
+    FOREIGN KEY (folder_id) REFERENCES folders(id) ON DELETE CASCADE,
+    INDEX idx_folder_id (folder_id)
+);
+
+-- Templates saved by users (built-in templates live in the code)
+CREATE TABLE project_templates (
+    id INT PRIMARY KEY AUTO_INCREMENT,
+    user_id INT NOT NULL,
+    name VARCHAR(100) NOT NULL,
+    description TEXT,
+    project_data JSON NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    INDEX idx_user_id (user_id)
+);
+
 -- Characters table (optional - for structured data)
 CREATE TABLE characters (
     id INT PRIMARY KEY AUTO_INCREMENT,
@@ -77,21 +224,143 @@ CREATE TABLE sessions (
     user_id INT NOT NULL,
     token_hash VARCHAR(255) NOT NULL,
     expires_at TIMESTAMP NOT NULL,
+    revoked_at TIMESTAMP NULL DEFAULT NULL,
+    user_agent VARCHAR(255),
+    ip_address VARCHAR(45),
+    last_seen_at TIMESTAMP NULL DEFAULT NULL, -- updated when the session's tokens are refreshed
     created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
     FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
     INDEX idx_user_id (user_id),
+    INDEX idx_token_hash (token_hash),
     INDEX idx_expires_at (expires_at)
 );
 
+-- Refresh tokens table (one token family per session, rotated on every use)
+CREATE TABLE refresh_tokens (
+    id INT PRIMARY KEY AUTO_INCREMENT,
+    session_id INT NOT NULL,
+    user_id INT NOT NULL,
+    token_hash VARCHAR(255) NOT NULL,
+    expires_at TIMESTAMP NOT NULL,
+    used_at TIMESTAMP NULL DEFAULT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (session_id) REFERENCES sessions(id) ON DELETE CASCADE,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    UNIQUE KEY unique_token_hash (token_hash),
+    INDEX idx_session_id (session_id)
+);
+
+-- Password reset tokens (single use, stored hashed)
+CREATE TABLE password_resets (
+    id INT PRIMARY KEY AUTO_INCREMENT,
+    user_id INT NOT NULL,
+    token_hash VARCHAR(255) NOT NULL,
+    expires_at TIMESTAMP NOT NULL,
+    used_at TIMESTAMP NULL DEFAULT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    UNIQUE KEY unique_token_hash (token_hash),
+    INDEX idx_user_id (user_id)
+);
+
+-- Email verification tokens (also used to confirm an email change)
+CREATE TABLE email_verifications (
+    id INT PRIMARY KEY AUTO_INCREMENT,
+    user_id INT NOT NULL,
+    email VARCHAR(255) NOT NULL, -- address being verified
+    token_hash VARCHAR(255) NOT NULL,
+    expires_at TIMESTAMP NOT NULL,
+    used_at TIMESTAMP NULL DEFAULT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    UNIQUE KEY unique_token_hash (token_hash),
+    INDEX idx_user_id (user_id)
+);
+
+-- Two-factor recovery codes (single use, stored hashed)
+CREATE TABLE recovery_codes (
+    id INT PRIMARY KEY AUTO_INCREMENT,
+    user_id INT NOT NULL,
+    code_hash VARCHAR(255) NOT NULL,
+    used_at TIMESTAMP NULL DEFAULT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    INDEX idx_user_code (user_id, code_hash)
+);
+
+-- External OpenID Connect identities linked to users
+CREATE TABLE user_identities (
+    id INT PRIMARY KEY AUTO_INCREMENT,
+    user_id INT NOT NULL,
+    provider VARCHAR(50) NOT NULL,
+    subject VARCHAR(255) NOT NULL, -- "sub" claim from the provider
+    email VARCHAR(255),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    last_login_at TIMESTAMP NULL DEFAULT NULL,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    UNIQUE KEY unique_provider_subject (provider, subject),
+    INDEX idx_user_id (user_id)
+);
+
+-- Pending OpenID Connect logins (state, nonce and PKCE verifier)
+CREATE TABLE oidc_states (
+    id INT PRIMARY KEY AUTO_INCREMENT,
+    state_hash VARCHAR(255) NOT NULL,
+    provider VARCHAR(50) NOT NULL,
+    nonce VARCHAR(255) NOT NULL,
+    code_verifier VARCHAR(255) NOT NULL,
+    user_id INT NULL, -- set when linking a provider to a logged in user
+    expires_at TIMESTAMP NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    UNIQUE KEY unique_state_hash (state_hash)
+);
+
+-- Personal access tokens for scripts; scopes is a comma separated list
+CREATE TABLE personal_access_tokens (
+    id INT PRIMARY KEY AUTO_INCREMENT,
+    user_id INT NOT NULL,
+    name VARCHAR(100) NOT NULL,
+    token_hash VARCHAR(255) NOT NULL,
+    token_prefix VARCHAR(20) NOT NULL,
+    scopes VARCHAR(255) NOT NULL,
+    expires_at TIMESTAMP NULL DEFAULT NULL,
+    last_used_at TIMESTAMP NULL DEFAULT NULL,
+    revoked_at TIMESTAMP NULL DEFAULT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    UNIQUE KEY unique_token_hash (token_hash),
+    INDEX idx_user_id (user_id)
+);
+
+-- Failed logins and registration attempts used for brute-force protection.
+-- Rows older than the configured windows are pruned automatically.
+CREATE TABLE auth_attempts (
+    id INT PRIMARY KEY AUTO_INCREMENT,
+    action VARCHAR(20) NOT NULL, -- login or register
+    email VARCHAR(255) NULL,
+    ip_address VARCHAR(45) NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    INDEX idx_action_email (action, email, created_at),
+    INDEX idx_action_ip (action, ip_address, created_at),
+    INDEX idx_created_at (created_at)
+);
+
 -- Insert sample data
-INSERT INTO users (user_name, email, password_hash) VALUES
-('John Doe', 'john@example.com', '$2a$10$rOyQZ8QqNEZjPz.KxKvDSOKGCGCqWqmNJ8GhCG8jjF3zCgCOKlOOm'), -- password: "password123"
-('Jane Smith', 'jane@example.com', '$2a$10$rOyQZ8QqNEZjPz.KxKvDSOKGCGCqWqmNJ8GhCG8jjF3zCgCOKlOOm');
-
-INSERT INTO projects (user_id, title, description, project_data) VALUES
-(1, 'My Fantasy Novel Characters', 'Character relationships for my fantasy novel', '{"elements":[],"metadata":{"version":"1.0"}}'),
-(1, 'Romance Story Diagram', 'Character web for romance story', '{"elements":[],"metadata":{"version":"1.0"}}'),
-(2, 'Sci-Fi Character Map', 'Space opera character relationships', '{"elements":[],"metadata":{"version":"1.0"}}');
+INSERT INTO users (username, user_name, email, password_hash) VALUES
+('john-doe', 'John Doe', 'john@example.com', '$2a$10$rOyQZ8QqNEZjPz.KxKvDSOKGCGCqWqmNJ8GhCG8jjF3zCgCOKlOOm'), -- password: "password123"
+('jane-smith', 'Jane Smith', 'jane@example.com', '$2a$10$rOyQZ8QqNEZjPz.KxKvDSOKGCGCqWqmNJ8GhCG8jjF3zCgCOKlOOm');
+
+INSERT INTO projects (user_id, title, description, project_data, visibility, genre) VALUES
+(1, 'My Fantasy Novel Characters', 'Character relationships for my fantasy novel', '{"elements":[],"metadata":{"version":"1.0"}}', 'public', 'fantasy'),
+(1, 'Romance Story Diagram', 'Character web for romance story', '{"elements":[],"metadata":{"version":"1.0"}}', 'public', 'romance'),
+(2, 'Sci-Fi Character Map', 'Space opera character relationships', '{"elements":[],"metadata":{"version":"1.0"}}', 'public', 'sci-fi');
+
+INSERT INTO project_tags (project_id, tag) VALUES
+(1, 'court-intrigue'),
+(1, 'magic'),
+(2, 'slow-burn'),
+(3, 'space-opera');
 
 ALTER TABLE projects MODIFY cover_image TEXT;
 
@@ -2,6 +2,7 @@
 package handlers
 
 import (
+    "errors"
     "net/http"
     "backend/internal/middleware"
     "backend/internal/models"
@@ -30,18 +31,24 @@ func (h *AuthHandler) Register(c *gin.Context) {
         return
     }
 
-    user, err := h.authService.Register(req)
+    err := h.authService.Register(req, c.ClientIP())
+    if errors.Is(err, services.ErrTooManyAttempts) {
+        c.JSON(http.StatusTooManyRequests, models.ErrorResponse{
+            Error:   "too_many_attempts",
+            Message: err.Error(),
+        })
+        return
+    }
     if err != nil {
-        c.JSON(http.StatusConflict, models.ErrorResponse{
+        c.JSON(http.StatusBadRequest, models.ErrorResponse{
             Error:   "registration_failed",
             Message: err.Error(),
         })
         return
     }
 
-    c.JSON(http.StatusCreated, models.SuccessResponse{
-        Message: "User registered successfully",
-        Data:    user,
+    c.JSON(http.StatusAccepted, models.SuccessResponse{
+        Message: "Check your inbox to finish signing up",
     })
 }
 
@@ -55,7 +62,14 @@ func (h *AuthHandler) Login(c *gin.Context) {
         return
     }
 
-    loginResponse, err := h.authService.Login(req)
+    loginResponse, err := h.authService.Login(req, models.ClientInfo{IPAddress: c.ClientIP(), UserAgent: c.Request.UserAgent()})
+    if errors.Is(err, services.ErrTooManyAttempts) {
+        c.JSON(http.StatusTooManyRequests, models.ErrorResponse{
+            Error:   "too_many_attempts",
+            Message: err.Error(),
+        })
+        return
+    }
     if err != nil {
         c.JSON(http.StatusUnauthorized, models.ErrorResponse{
             Error:   "login_failed",
@@ -127,10 +141,26 @@ func (h *AuthHandler) UpdateProfile(c *gin.Context) {
 }
 
 func (h *AuthHandler) Logout(c *gin.Context) {
-    // In a stateless JWT system, logout is typically handled client-side
-    // by removing the token from storage. However, we can provide a logout endpoint
-    // for consistency and future token blacklisting if needed.
-    
+    userID, exists := middleware.GetUserID(c)
+    if !exists {
+        c.JSON(http.StatusUnauthorized, models.ErrorResponse{
+            Error:   "unauthorized",
+            Message: "User not authenticated",
+        })
+        return
+    }
+
+    sessionID, _ := middleware.GetSessionID(c)
+
+    // Revoke the session so the token can no longer be used
+    if err := h.authService.Logout(userID, sessionID); err != nil {
+        c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+            Error:   "logout_failed",
+            Message: err.Error(),
+        })
+        return
+    }
+
     c.JSON(http.StatusOK, models.SuccessResponse{
         Message: "Logged out successfully",
     })
@@ -158,10 +188,13 @@ func (h *AuthHandler) ValidateToken(c *gin.Context) {
     c.JSON(http.StatusOK, models.SuccessResponse{
         Message: "Token is valid",
         Data: models.UserProfile{
-            ID:           user.ID,
-            UserName:     user.UserName,
-            Email:        user.Email,
-            ProfileImage: user.ProfileImage,
+            ID:            user.ID,
+            Username:      user.Username,
+            UserName:      user.UserName,
+            Email:         user.Email,
+            Role:          user.Role,
+            ProfileImage:  user.ProfileImage,
+            EmailVerified: user.EmailVerified,
         },
     })
 }
\ No newline at end of file
@@ -73,7 +73,7 @@ func (h *ProjectAPIHandler) GetUserProjects(c *gin.Context) {
         return
     }
 
-    projects, err := h.projectService.GetProjectsByUser(userID)
+    projects, err := h.projectService.GetProjectsByUser(userID, models.ProjectFilter{})
     if err != nil {
         c.JSON(http.StatusInternalServerError, models.APIResponse{
             Success: false,
@@ -90,7 +90,7 @@ func (h *ProjectAPIHandler) GetUserProjects(c *gin.Context) {
 
 // GetAllProjects handles getting all public projects
 func (h *ProjectAPIHandler) GetAllProjects(c *gin.Context) {
-    projects, err := h.projectService.GetAllProjects()
+    projects, err := h.projectService.GetAllProjects(models.ProjectFilter{})
     if err != nil {
         c.JSON(http.StatusInternalServerError, models.APIResponse{
             Success: false,
@@ -1,11 +1,13 @@
 package middleware
 
 import (
+	authmiddleware "backend/internal/middleware"
 	"backend/internal/models"
 	"backend/internal/services"
 	"fmt"
 	"net/http"
 	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 )
@@ -38,7 +40,7 @@ func APIAuthMiddleware(authService *services.AuthService) gin.HandlerFunc {
         token := tokenParts[1]
 
         // Validate token
-        userID, err := authService.ValidateToken(token)
+        claims, err := authService.ValidateToken(token)
         if err != nil {
             c.JSON(http.StatusUnauthorized, models.APIResponse{
                 Success: false,
@@ -48,8 +50,10 @@ func APIAuthMiddleware(authService *services.AuthService) gin.HandlerFunc {
             return
         }
 
-        // Store user ID in context for use in handlers
-        c.Set("user_id", userID)
+        // Store user and session IDs in context for use in handlers
+        c.Set("user_id", claims.UserID)
+        c.Set("session_id", claims.SessionID)
+        c.Set("user_role", claims.Role)
         c.Next()
     }
 }
@@ -63,8 +67,9 @@ func OptionalAPIAuthMiddleware(authService *services.AuthService) gin.HandlerFun
             tokenParts := strings.Split(authHeader, " ")
             if len(tokenParts) == 2 && tokenParts[0] == "Bearer" {
                 token := tokenParts[1]
-                if userID, err := authService.ValidateToken(token); err == nil {
-                    c.Set("user_id", userID)
+                if claims, err := authService.ValidateToken(token); err == nil {
+                    c.Set("user_id", claims.UserID)
+                    c.Set("session_id", claims.SessionID)
                 }
             }
         }
@@ -105,14 +110,9 @@ func CORSMiddleware(allowedOrigins []string) gin.HandlerFunc {
     }
 }
 
-// RateLimitMiddleware provides basic rate limiting
-func RateLimitMiddleware() gin.HandlerFunc {
-    // This is a simple implementation - in production you'd want to use Redis
-    // or a proper rate limiting library
-    return func(c *gin.Context) {
-        // Implementation would go here
-        c.Next()
-    }
+// RateLimitMiddleware limits each client IP to limit requests per window
+func RateLimitMiddleware(limit int, window time.Duration) gin.HandlerFunc {
+    return authmiddleware.RateLimitMiddleware(limit, window)
 }
 
 // LoggingMiddleware logs API requests
@@ -88,6 +88,10 @@ func SecurityLoggingMiddleware() gin.HandlerFunc {
             log.Printf("[SECURITY] Unauthorized access attempt from %s - Path: %s", 
                 c.ClientIP(), c.Request.URL.Path)
         }
+        if c.Writer.Status() == 429 {
+            log.Printf("[SECURITY] Throttled request from %s - Path: %s", 
+                c.ClientIP(), c.Request.URL.Path)
+        }
     }
 }
 
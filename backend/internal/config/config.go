@@ -1,6 +1,7 @@
 package config
 
 import (
+    "errors"
     "os"
     "strconv"
     "strings"
@@ -14,6 +15,10 @@ type Config struct {
     CORS     CORSConfig
     Upload   UploadConfig
     Session  SessionConfig
+    Auth     AuthConfig
+    Mail     MailConfig
+    OIDC     OIDCConfig
+    Projects ProjectsConfig
 }
 
 type DatabaseConfig struct {
@@ -25,12 +30,29 @@ type DatabaseConfig struct {
 }
 
 type ServerConfig struct {
-    Port string
-    Mode string
+    Port        string
+    Mode        string
+    FrontendURL string
+    // TrustedProxies may set X-Forwarded-For; by default no proxy is trusted
+    // and the client IP is the address of the connection
+    TrustedProxies []string
 }
 
+// DefaultJWTSecret is the placeholder secret used when JWT_SECRET is unset
+const DefaultJWTSecret = "your-default-secret-change-this"
+
 type JWTConfig struct {
-    Secret string
+    // Secret signs HS256 access tokens and the short-lived two-factor
+    // challenge tokens, which are never published
+    Secret              string
+    AccessTokenDuration time.Duration
+
+    // Algorithm is HS256, RS256 or EdDSA. RS256 and EdDSA sign access tokens
+    // with the private key in SigningKeyFile; VerificationKeyFiles hold
+    // previous public keys that are still accepted after a rotation.
+    Algorithm            string
+    SigningKeyFile       string
+    VerificationKeyFiles []string
 }
 
 type CORSConfig struct {
@@ -48,6 +70,72 @@ type SessionConfig struct {
     Duration time.Duration
 }
 
+type AuthConfig struct {
+    PasswordResetTTL     time.Duration
+    EmailVerificationTTL time.Duration
+    // RequireVerifiedEmailToPublish hides projects of unverified users from everyone else
+    RequireVerifiedEmailToPublish bool
+
+    // Password policy
+    PasswordMinLength     int
+    PasswordRequireUpper  bool
+    PasswordRequireLower  bool
+    PasswordRequireDigit  bool
+    BreachedPasswordsFile string // optional, one password per line
+
+    // Two-factor authentication
+    TOTPIssuer            string
+    TwoFactorChallengeTTL time.Duration
+
+    // Brute-force protection. Failed logins are counted per email and per
+    // client IP; delays double from LoginThrottleAfter failures on and the
+    // email or IP is locked out at the max.
+    LoginThrottleAfter    int
+    LoginMaxAttempts      int
+    LoginIPMaxAttempts    int
+    LoginAttemptWindow    time.Duration
+    LoginLockoutDuration  time.Duration
+    RegisterIPMaxAttempts int
+    RegisterAttemptWindow time.Duration
+
+    // Per-IP request limit on the public authentication endpoints
+    RateLimitRequests int
+    RateLimitWindow   time.Duration
+}
+
+type ProjectsConfig struct {
+    // TrashRetention is how long deleted projects can be restored before
+    // they are purged; the purge runs every TrashPurgeInterval
+    TrashRetention     time.Duration
+    TrashPurgeInterval time.Duration
+}
+
+type MailConfig struct {
+    Driver       string // smtp, file or log
+    From         string
+    SMTPHost     string
+    SMTPPort     string
+    SMTPUser     string
+    SMTPPassword string
+    FileDir      string
+}
+
+type OIDCConfig struct {
+    // RedirectBaseURL is the public URL of this API; callbacks are served at
+    // <RedirectBaseURL>/api/auth/oidc/<provider>/callback
+    RedirectBaseURL string
+    StateTTL        time.Duration
+    Providers       []OIDCProviderConfig
+}
+
+type OIDCProviderConfig struct {
+    Name         string
+    Issuer       string
+    ClientID     string
+    ClientSecret string
+    Scopes       []string
+}
+
 func Load() *Config {
     return &Config{
         Database: DatabaseConfig{
@@ -58,11 +146,17 @@ func Load() *Config {
             Name:     getEnv("DB_NAME", "novelsync"),
         },
         Server: ServerConfig{
-            Port: getEnv("PORT", "8080"),
-            Mode: getEnv("GIN_MODE", "debug"),
+            Port:           getEnv("PORT", "8080"),
+            Mode:           getEnv("GIN_MODE", "debug"),
+            FrontendURL:    getEnv("FRONTEND_URL", "http://localhost:3000"),
+            TrustedProxies: splitList(getEnv("TRUSTED_PROXIES", "")),
         },
         JWT: JWTConfig{
-            Secret: getEnv("JWT_SECRET", "your-default-secret-change-this"),
+            Secret:               getEnv("JWT_SECRET", DefaultJWTSecret),
+            AccessTokenDuration:  getEnvDuration("ACCESS_TOKEN_DURATION", 15*time.Minute),
+            Algorithm:            getEnv("JWT_ALGORITHM", "HS256"),
+            SigningKeyFile:       getEnv("JWT_SIGNING_KEY_FILE", ""),
+            VerificationKeyFiles: splitList(getEnv("JWT_VERIFICATION_KEY_FILES", "")),
         },
         CORS: CORSConfig{
             AllowedOrigins: strings.Split(getEnv("CORS_ALLOWED_ORIGINS", "http://localhost:3000,https://novelsync-frontend.onrender.com"), ","),
@@ -74,9 +168,94 @@ func Load() *Config {
             Path:    getEnv("UPLOAD_PATH", "./uploads"),
         },
         Session: SessionConfig{
-            Duration: getEnvDuration("SESSION_DURATION", 24*time.Hour),
+            Duration: getEnvDuration("SESSION_DURATION", 30*24*time.Hour), // refresh token lifetime
+        },
+        Auth: AuthConfig{
+            PasswordResetTTL:              getEnvDuration("PASSWORD_RESET_TTL", time.Hour),
+            EmailVerificationTTL:          getEnvDuration("EMAIL_VERIFICATION_TTL", 48*time.Hour),
+            RequireVerifiedEmailToPublish: getEnvBool("REQUIRE_VERIFIED_EMAIL_TO_PUBLISH", false),
+            PasswordMinLength:             getEnvInt("PASSWORD_MIN_LENGTH", 8),
+            PasswordRequireUpper:          getEnvBool("PASSWORD_REQUIRE_UPPER", true),
+            PasswordRequireLower:          getEnvBool("PASSWORD_REQUIRE_LOWER", true),
+            PasswordRequireDigit:          getEnvBool("PASSWORD_REQUIRE_DIGIT", true),
+            BreachedPasswordsFile:         getEnv("BREACHED_PASSWORDS_FILE", ""),
+            TOTPIssuer:                    getEnv("TOTP_ISSUER", "NovelSync"),
+            TwoFactorChallengeTTL:         getEnvDuration("TWO_FACTOR_CHALLENGE_TTL", 5*time.Minute),
+            LoginThrottleAfter:            getEnvInt("LOGIN_THROTTLE_AFTER", 3),
+            LoginMaxAttempts:              getEnvInt("LOGIN_MAX_ATTEMPTS", 10),
+            LoginIPMaxAttempts:            getEnvInt("LOGIN_IP_MAX_ATTEMPTS", 50),
+            LoginAttemptWindow:            getEnvDuration("LOGIN_ATTEMPT_WINDOW", 15*time.Minute),
+            LoginLockoutDuration:          getEnvDuration("LOGIN_LOCKOUT_DURATION", 15*time.Minute),
+            RegisterIPMaxAttempts:         getEnvInt("REGISTER_IP_MAX_ATTEMPTS", 10),
+            RegisterAttemptWindow:         getEnvDuration("REGISTER_ATTEMPT_WINDOW", time.Hour),
+            RateLimitRequests:             getEnvInt("AUTH_RATE_LIMIT", 30),
+            RateLimitWindow:               getEnvDuration("AUTH_RATE_LIMIT_WINDOW", time.Minute),
+        },
+        Mail: MailConfig{
+            Driver:       getEnv("MAIL_DRIVER", "log"),
+            From:         getEnv("MAIL_FROM", "NovelSync <no-reply@novelsync.local>"),
+            SMTPHost:     getEnv("SMTP_HOST", "localhost"),
+            SMTPPort:     getEnv("SMTP_PORT", "587"),
+            SMTPUser:     getEnv("SMTP_USER", ""),
+            SMTPPassword: getEnv("SMTP_PASSWORD", ""),
+            FileDir:      getEnv("MAIL_FILE_DIR", "./mail"),
         },
+        OIDC: OIDCConfig{
+            RedirectBaseURL: getEnv("OIDC_REDIRECT_BASE_URL", "http://localhost:8080"),
+            StateTTL:        getEnvDuration("OIDC_STATE_TTL", 10*time.Minute),
+            Providers:       loadOIDCProviders(),
+        },
+        Projects: ProjectsConfig{
+            TrashRetention:     getEnvDuration("TRASH_RETENTION", 30*24*time.Hour),
+            TrashPurgeInterval: getEnvDuration("TRASH_PURGE_INTERVAL", time.Hour),
+        },
+    }
+}
+
+// Validate rejects configurations that are unsafe to run in production
+func (c *Config) Validate() error {
+    if c.Server.Mode == "release" && (c.JWT.Secret == DefaultJWTSecret || c.JWT.Secret == "") {
+        return errors.New("JWT_SECRET must be set to a random value in release mode")
+    }
+    // The log mailer writes reset and verification links to the server log
+    if c.Server.Mode == "release" && (c.Mail.Driver == "log" || c.Mail.Driver == "") {
+        return errors.New("MAIL_DRIVER must be smtp or file in release mode")
     }
+    return nil
+}
+
+// loadOIDCProviders reads the providers listed in OIDC_PROVIDERS, e.g.
+// OIDC_PROVIDERS=google configures OIDC_GOOGLE_ISSUER, OIDC_GOOGLE_CLIENT_ID,
+// OIDC_GOOGLE_CLIENT_SECRET and OIDC_GOOGLE_SCOPES.
+func loadOIDCProviders() []OIDCProviderConfig {
+    providers := []OIDCProviderConfig{}
+    for _, name := range strings.Split(getEnv("OIDC_PROVIDERS", ""), ",") {
+        name = strings.ToLower(strings.TrimSpace(name))
+        if name == "" {
+            continue
+        }
+
+        prefix := "OIDC_" + strings.ToUpper(name) + "_"
+        providers = append(providers, OIDCProviderConfig{
+            Name:         name,
+            Issuer:       getEnv(prefix+"ISSUER", ""),
+            ClientID:     getEnv(prefix+"CLIENT_ID", ""),
+            ClientSecret: getEnv(prefix+"CLIENT_SECRET", ""),
+            Scopes:       strings.Split(getEnv(prefix+"SCOPES", "openid,email,profile"), ","),
+        })
+    }
+    return providers
+}
+
+// splitList splits a comma separated value, skipping empty entries
+func splitList(value string) []string {
+    items := []string{}
+    for _, item := range strings.Split(value, ",") {
+        if item = strings.TrimSpace(item); item != "" {
+            items = append(items, item)
+        }
+    }
+    return items
 }
 
 func getEnv(key, defaultValue string) string {
@@ -86,6 +265,15 @@ func getEnv(key, defaultValue string) string {
     return defaultValue
 }
 
+func getEnvInt(key string, defaultValue int) int {
+    if value := os.Getenv(key); value != "" {
+        if parsed, err := strconv.Atoi(value); err == nil {
+            return parsed
+        }
+    }
+    return defaultValue
+}
+
 func getEnvInt64(key string, defaultValue int64) int64 {
     if value := os.Getenv(key); value != "" {
         if parsed, err := strconv.ParseInt(value, 10, 64); err == nil {
@@ -103,3 +291,12 @@ func getEnvDuration(key string, defaultValue time.Duration) time.Duration {
     }
     return defaultValue
 }
+
+func getEnvBool(key string, defaultValue bool) bool {
+    if value := os.Getenv(key); value != "" {
+        if parsed, err := strconv.ParseBool(value); err == nil {
+            return parsed
+        }
+    }
+    return defaultValue
+}
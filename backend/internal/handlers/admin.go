@@ -0,0 +1,311 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"backend/internal/middleware"
+	"backend/internal/models"
+	"backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AdminHandler handles the moderation endpoints under /admin.
+type AdminHandler struct {
+	adminService *services.AdminService
+}
+
+func NewAdminHandler(adminService *services.AdminService) *AdminHandler {
+	return &AdminHandler{
+		adminService: adminService,
+	}
+}
+
+// GetUsers handles GET /admin/users
+func (h *AdminHandler) GetUsers(c *gin.Context) {
+	var filter models.AdminUserFilter
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "invalid_request",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	users, err := h.adminService.ListUsers(filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:   "fetch_failed",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse{
+		Message: "Users retrieved successfully",
+		Data:    users,
+	})
+}
+
+// SuspendUser handles POST /admin/users/:id/suspend
+func (h *AdminHandler) SuspendUser(c *gin.Context) {
+	actorID, actorRole, userID, ok := h.moderationTarget(c)
+	if !ok {
+		return
+	}
+
+	var req models.ModerationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "invalid_request",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	err := h.adminService.SuspendUser(actorID, actorRole, userID, req.Reason)
+	if respondModerationError(c, err, "suspend_failed") {
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse{
+		Message: "User suspended successfully",
+	})
+}
+
+// UnsuspendUser handles POST /admin/users/:id/unsuspend
+func (h *AdminHandler) UnsuspendUser(c *gin.Context) {
+	actorID, actorRole, userID, ok := h.moderationTarget(c)
+	if !ok {
+		return
+	}
+
+	err := h.adminService.UnsuspendUser(actorID, actorRole, userID)
+	if respondModerationError(c, err, "unsuspend_failed") {
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse{
+		Message: "User unsuspended successfully",
+	})
+}
+
+// ForcePasswordReset handles POST /admin/users/:id/password-reset
+func (h *AdminHandler) ForcePasswordReset(c *gin.Context) {
+	actorID, actorRole, userID, ok := h.moderationTarget(c)
+	if !ok {
+		return
+	}
+
+	err := h.adminService.ForcePasswordReset(actorID, actorRole, userID)
+	if respondModerationError(c, err, "reset_failed") {
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse{
+		Message: "Password cleared and reset email sent",
+	})
+}
+
+// DeleteUser handles DELETE /admin/users/:id
+func (h *AdminHandler) DeleteUser(c *gin.Context) {
+	actorID, actorRole, userID, ok := h.moderationTarget(c)
+	if !ok {
+		return
+	}
+
+	err := h.adminService.DeleteUser(actorID, actorRole, userID)
+	if respondModerationError(c, err, "deletion_failed") {
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse{
+		Message: "User deleted successfully",
+	})
+}
+
+// UpdateRole handles PUT /admin/users/:id/role
+func (h *AdminHandler) UpdateRole(c *gin.Context) {
+	actorID, _, userID, ok := h.moderationTarget(c)
+	if !ok {
+		return
+	}
+
+	var req models.UpdateRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "invalid_request",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	err := h.adminService.SetRole(actorID, userID, req.Role)
+	if respondModerationError(c, err, "update_failed") {
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse{
+		Message: "Role updated successfully",
+	})
+}
+
+// GetProjects handles GET /admin/projects
+func (h *AdminHandler) GetProjects(c *gin.Context) {
+	var filter models.AdminProjectFilter
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "invalid_request",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	projects, err := h.adminService.ListProjects(filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:   "fetch_failed",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse{
+		Message: "Projects retrieved successfully",
+		Data:    projects,
+	})
+}
+
+// UnpublishProject handles POST /admin/projects/:id/unpublish
+func (h *AdminHandler) UnpublishProject(c *gin.Context) {
+	actorID, _, projectID, ok := h.moderationTarget(c)
+	if !ok {
+		return
+	}
+
+	var req models.ModerationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "invalid_request",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	err := h.adminService.UnpublishProject(actorID, projectID, req.Reason)
+	if respondModerationError(c, err, "unpublish_failed") {
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse{
+		Message: "Project unpublished successfully",
+	})
+}
+
+// RepublishProject handles POST /admin/projects/:id/republish
+func (h *AdminHandler) RepublishProject(c *gin.Context) {
+	actorID, _, projectID, ok := h.moderationTarget(c)
+	if !ok {
+		return
+	}
+
+	err := h.adminService.RepublishProject(actorID, projectID)
+	if respondModerationError(c, err, "republish_failed") {
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse{
+		Message: "Project republished successfully",
+	})
+}
+
+// PickProject handles POST /admin/projects/:id/pick
+func (h *AdminHandler) PickProject(c *gin.Context) {
+	actorID, _, projectID, ok := h.moderationTarget(c)
+	if !ok {
+		return
+	}
+
+	err := h.adminService.PickProject(actorID, projectID)
+	if respondModerationError(c, err, "pick_failed") {
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse{
+		Message: "Project added to editor picks",
+	})
+}
+
+// UnpickProject handles DELETE /admin/projects/:id/pick
+func (h *AdminHandler) UnpickProject(c *gin.Context) {
+	actorID, _, projectID, ok := h.moderationTarget(c)
+	if !ok {
+		return
+	}
+
+	err := h.adminService.UnpickProject(actorID, projectID)
+	if respondModerationError(c, err, "unpick_failed") {
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse{
+		Message: "Project removed from editor picks",
+	})
+}
+
+// moderationTarget reads the acting user and the numeric :id parameter. It
+// writes the error response itself and reports whether to continue.
+func (h *AdminHandler) moderationTarget(c *gin.Context) (int, string, int, bool) {
+	actorID, exists := middleware.GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, models.ErrorResponse{
+			Error:   "unauthorized",
+			Message: "User not authenticated",
+		})
+		return 0, "", 0, false
+	}
+	actorRole, _ := middleware.GetUserRole(c)
+
+	targetID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "invalid_id",
+			Message: "ID must be a number",
+		})
+		return 0, "", 0, false
+	}
+
+	return actorID, actorRole, targetID, true
+}
+
+// respondModerationError maps moderation errors to responses. It reports
+// whether a response was written.
+func respondModerationError(c *gin.Context, err error, failCode string) bool {
+	switch {
+	case err == nil:
+		return false
+	case errors.Is(err, services.ErrUserNotFound):
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Error:   "user_not_found",
+			Message: err.Error(),
+		})
+	case errors.Is(err, services.ErrProjectNotFound):
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Error:   "project_not_found",
+			Message: err.Error(),
+		})
+	case errors.Is(err, services.ErrCannotModerateSelf), errors.Is(err, services.ErrInsufficientRole):
+		c.JSON(http.StatusForbidden, models.ErrorResponse{
+			Error:   "forbidden",
+			Message: err.Error(),
+		})
+	default:
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:   failCode,
+			Message: err.Error(),
+		})
+	}
+	return true
+}
@@ -2,8 +2,10 @@
 package handlers
 
 import (
+    "errors"
     "fmt"
     "net/http"
+    "strconv"
     "backend/internal/middleware"
     "backend/internal/models"
     "backend/internal/services"
@@ -31,18 +33,42 @@ func (h *AuthHandler) Register(c *gin.Context) {
         return
     }
 
-    user, err := h.authService.Register(req)
-    if err != nil {
+    err := h.authService.Register(req, c.ClientIP())
+    if respondThrottled(c, err) {
+        return
+    }
+    if errors.Is(err, services.ErrWeakPassword) {
+        c.JSON(http.StatusBadRequest, models.ErrorResponse{
+            Error:   "weak_password",
+            Message: err.Error(),
+        })
+        return
+    }
+    if errors.Is(err, services.ErrInvalidUsername) {
+        c.JSON(http.StatusBadRequest, models.ErrorResponse{
+            Error:   "invalid_username",
+            Message: err.Error(),
+        })
+        return
+    }
+    if errors.Is(err, services.ErrUsernameTaken) {
         c.JSON(http.StatusConflict, models.ErrorResponse{
-            Error:   "registration_failed",
+            Error:   "username_taken",
             Message: err.Error(),
         })
         return
     }
+    if err != nil {
+        c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+            Error:   "registration_failed",
+            Message: "Failed to register user",
+        })
+        return
+    }
 
-    c.JSON(http.StatusCreated, models.SuccessResponse{
-        Message: "User registered successfully",
-        Data:    user,
+    // The same response whether or not the email already had an account
+    c.JSON(http.StatusAccepted, models.SuccessResponse{
+        Message: "Check your inbox to finish signing up",
     })
 }
 
@@ -56,18 +82,71 @@ func (h *AuthHandler) Login(c *gin.Context) {
         return
     }
 
-    loginResponse, err := h.authService.Login(req)
-    if err != nil {
+    loginResponse, err := h.authService.Login(req, clientInfo(c))
+    if respondThrottled(c, err) {
+        return
+    }
+    if errors.Is(err, services.ErrInvalidCredentials) {
         c.JSON(http.StatusUnauthorized, models.ErrorResponse{
             Error:   "login_failed",
             Message: err.Error(),
         })
         return
     }
+    if errors.Is(err, services.ErrAccountSuspended) {
+        c.JSON(http.StatusForbidden, models.ErrorResponse{
+            Error:   "account_suspended",
+            Message: err.Error(),
+        })
+        return
+    }
+    if err != nil {
+        c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+            Error:   "login_failed",
+            Message: "Login failed, please try again later",
+        })
+        return
+    }
 
     c.JSON(http.StatusOK, loginResponse)
 }
 
+func (h *AuthHandler) RefreshToken(c *gin.Context) {
+    var req models.RefreshTokenRequest
+    if err := c.ShouldBindJSON(&req); err != nil {
+        c.JSON(http.StatusBadRequest, models.ErrorResponse{
+            Error:   "invalid_request",
+            Message: err.Error(),
+        })
+        return
+    }
+
+    tokens, err := h.authService.RefreshToken(req.RefreshToken, clientInfo(c))
+    if errors.Is(err, services.ErrRefreshTokenReused) {
+        c.JSON(http.StatusUnauthorized, models.ErrorResponse{
+            Error:   "refresh_token_reused",
+            Message: "Refresh token reuse detected, please log in again",
+        })
+        return
+    }
+    if errors.Is(err, services.ErrRefreshTokenInvalid) {
+        c.JSON(http.StatusUnauthorized, models.ErrorResponse{
+            Error:   "invalid_refresh_token",
+            Message: err.Error(),
+        })
+        return
+    }
+    if err != nil {
+        c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+            Error:   "refresh_failed",
+            Message: err.Error(),
+        })
+        return
+    }
+
+    c.JSON(http.StatusOK, tokens)
+}
+
 func (h *AuthHandler) GetProfile(c *gin.Context) {
     userID, exists := middleware.GetUserID(c)
     if !exists {
@@ -118,6 +197,20 @@ func (h *AuthHandler) UpdateProfile(c *gin.Context) {
     fmt.Printf("UpdateProfile request - UserID: %d, Request: %+v\n", userID, req)
 
     profile, err := h.authService.UpdateProfile(userID, req)
+    if errors.Is(err, services.ErrInvalidUsername) {
+        c.JSON(http.StatusBadRequest, models.ErrorResponse{
+            Error:   "invalid_username",
+            Message: err.Error(),
+        })
+        return
+    }
+    if errors.Is(err, services.ErrUsernameTaken) {
+        c.JSON(http.StatusConflict, models.ErrorResponse{
+            Error:   "username_taken",
+            Message: err.Error(),
+        })
+        return
+    }
     if err != nil {
         // Log the error for debugging
         fmt.Printf("UpdateProfile service error: %v\n", err)
@@ -135,10 +228,26 @@ func (h *AuthHandler) UpdateProfile(c *gin.Context) {
 }
 
 func (h *AuthHandler) Logout(c *gin.Context) {
-    // In a stateless JWT system, logout is typically handled client-side
-    // by removing the token from storage. However, we can provide a logout endpoint
-    // for consistency and future token blacklisting if needed.
-    
+    userID, exists := middleware.GetUserID(c)
+    if !exists {
+        c.JSON(http.StatusUnauthorized, models.ErrorResponse{
+            Error:   "unauthorized",
+            Message: "User not authenticated",
+        })
+        return
+    }
+
+    sessionID, _ := middleware.GetSessionID(c)
+
+    // Revoke the session so the token can no longer be used
+    if err := h.authService.Logout(userID, sessionID); err != nil {
+        c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+            Error:   "logout_failed",
+            Message: err.Error(),
+        })
+        return
+    }
+
     c.JSON(http.StatusOK, models.SuccessResponse{
         Message: "Logged out successfully",
     })
@@ -166,10 +275,38 @@ func (h *AuthHandler) ValidateToken(c *gin.Context) {
     c.JSON(http.StatusOK, models.SuccessResponse{
         Message: "Token is valid",
         Data: models.UserProfile{
-            ID:           user.ID,
-            UserName:     user.UserName,
-            Email:        user.Email,
-            ProfileImage: user.ProfileImage,
+            ID:            user.ID,
+            Username:      user.Username,
+            UserName:      user.UserName,
+            Email:         user.Email,
+            Role:          user.Role,
+            ProfileImage:  user.ProfileImage,
+            EmailVerified: user.EmailVerified,
         },
     })
-}
\ No newline at end of file
+}
+
+// respondThrottled answers throttled requests with 429 and a Retry-After
+// header. It reports whether a response was written.
+func respondThrottled(c *gin.Context, err error) bool {
+    var throttleErr *services.ThrottleError
+    if !errors.As(err, &throttleErr) {
+        return false
+    }
+
+    seconds := int(throttleErr.RetryAfter.Seconds()) + 1
+    c.Header("Retry-After", strconv.Itoa(seconds))
+    c.JSON(http.StatusTooManyRequests, models.ErrorResponse{
+        Error:   "too_many_attempts",
+        Message: throttleErr.Error(),
+    })
+    return true
+}
+
+// clientInfo returns the IP address and user agent recorded for new sessions
+func clientInfo(c *gin.Context) models.ClientInfo {
+    return models.ClientInfo{
+        IPAddress: c.ClientIP(),
+        UserAgent: c.Request.UserAgent(),
+    }
+}
@@ -0,0 +1,169 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"backend/internal/middleware"
+	"backend/internal/models"
+	"backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CommentHandler handles comment threads on projects.
+type CommentHandler struct {
+	commentService *services.CommentService
+}
+
+func NewCommentHandler(commentService *services.CommentService) *CommentHandler {
+	return &CommentHandler{
+		commentService: commentService,
+	}
+}
+
+// GetComments handles GET /projects/:id/comments
+func (h *CommentHandler) GetComments(c *gin.Context) {
+	projectID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "invalid_project_id",
+			Message: "Project ID must be a number",
+		})
+		return
+	}
+
+	userID, _ := middleware.GetUserID(c)
+
+	comments, err := h.commentService.ListComments(projectID, userID)
+	if err != nil {
+		respondCommentError(c, err, "fetch_failed")
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse{
+		Message: "Comments retrieved successfully",
+		Data:    comments,
+	})
+}
+
+// CreateComment handles POST /projects/:id/comments
+func (h *CommentHandler) CreateComment(c *gin.Context) {
+	userID, projectID, ok := projectRequest(c)
+	if !ok {
+		return
+	}
+
+	var req models.CreateCommentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "invalid_request",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	comment, err := h.commentService.CreateComment(projectID, userID, req)
+	if err != nil {
+		respondCommentError(c, err, "comment_failed")
+		return
+	}
+
+	c.JSON(http.StatusCreated, models.SuccessResponse{
+		Message: "Comment added successfully",
+		Data:    comment,
+	})
+}
+
+// UpdateComment handles PUT /projects/:id/comments/:commentId
+func (h *CommentHandler) UpdateComment(c *gin.Context) {
+	userID, projectID, ok := projectRequest(c)
+	if !ok {
+		return
+	}
+	commentID, ok := commentParam(c)
+	if !ok {
+		return
+	}
+
+	var req models.UpdateCommentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "invalid_request",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	comment, err := h.commentService.UpdateComment(projectID, commentID, userID, req)
+	if err != nil {
+		respondCommentError(c, err, "update_failed")
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse{
+		Message: "Comment updated successfully",
+		Data:    comment,
+	})
+}
+
+// DeleteComment handles DELETE /projects/:id/comments/:commentId
+func (h *CommentHandler) DeleteComment(c *gin.Context) {
+	userID, projectID, ok := projectRequest(c)
+	if !ok {
+		return
+	}
+	commentID, ok := commentParam(c)
+	if !ok {
+		return
+	}
+
+	if err := h.commentService.DeleteComment(projectID, commentID, userID); err != nil {
+		respondCommentError(c, err, "deletion_failed")
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse{
+		Message: "Comment deleted successfully",
+	})
+}
+
+func commentParam(c *gin.Context) (int, bool) {
+	commentID, err := strconv.Atoi(c.Param("commentId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "invalid_comment_id",
+			Message: "Comment ID must be a number",
+		})
+		return 0, false
+	}
+	return commentID, true
+}
+
+func respondCommentError(c *gin.Context, err error, failCode string) {
+	switch {
+	case errors.Is(err, services.ErrCommentNotFound):
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Error:   "comment_not_found",
+			Message: err.Error(),
+		})
+	case errors.Is(err, services.ErrCommentForbidden):
+		c.JSON(http.StatusForbidden, models.ErrorResponse{
+			Error:   "forbidden",
+			Message: err.Error(),
+		})
+	case errors.Is(err, services.ErrCommentEmpty):
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "empty_comment",
+			Message: err.Error(),
+		})
+	case errors.Is(err, services.ErrElementNotFound):
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "invalid_element",
+			Message: err.Error(),
+		})
+	default:
+		respondProjectError(c, err, failCode)
+	}
+}
@@ -0,0 +1,142 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"backend/internal/middleware"
+	"backend/internal/models"
+	"backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// EmailHandler handles email verification and email change endpoints.
+type EmailHandler struct {
+	emailService *services.EmailService
+}
+
+func NewEmailHandler(emailService *services.EmailService) *EmailHandler {
+	return &EmailHandler{
+		emailService: emailService,
+	}
+}
+
+// VerifyEmail handles POST /email/verify
+func (h *EmailHandler) VerifyEmail(c *gin.Context) {
+	var req models.VerifyEmailRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "invalid_request",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	err := h.emailService.VerifyEmail(req.Token)
+	if errors.Is(err, services.ErrVerificationTokenInvalid) {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "invalid_verification_token",
+			Message: err.Error(),
+		})
+		return
+	}
+	if errors.Is(err, services.ErrEmailTaken) {
+		c.JSON(http.StatusConflict, models.ErrorResponse{
+			Error:   "email_taken",
+			Message: err.Error(),
+		})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:   "verification_failed",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse{
+		Message: "Email verified successfully",
+	})
+}
+
+// ResendVerification handles POST /email/resend
+func (h *EmailHandler) ResendVerification(c *gin.Context) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, models.ErrorResponse{
+			Error:   "unauthorized",
+			Message: "User not authenticated",
+		})
+		return
+	}
+
+	err := h.emailService.ResendVerification(userID)
+	if errors.Is(err, services.ErrEmailAlreadyVerified) {
+		c.JSON(http.StatusConflict, models.ErrorResponse{
+			Error:   "already_verified",
+			Message: err.Error(),
+		})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:   "resend_failed",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse{
+		Message: "Verification email sent",
+	})
+}
+
+// ChangeEmail handles PUT /user/email
+func (h *EmailHandler) ChangeEmail(c *gin.Context) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, models.ErrorResponse{
+			Error:   "unauthorized",
+			Message: "User not authenticated",
+		})
+		return
+	}
+
+	var req models.ChangeEmailRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "invalid_request",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	err := h.emailService.RequestEmailChange(userID, req)
+	if errors.Is(err, services.ErrInvalidPassword) {
+		c.JSON(http.StatusUnauthorized, models.ErrorResponse{
+			Error:   "invalid_password",
+			Message: err.Error(),
+		})
+		return
+	}
+	if errors.Is(err, services.ErrEmailTaken) {
+		c.JSON(http.StatusConflict, models.ErrorResponse{
+			Error:   "email_taken",
+			Message: err.Error(),
+		})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:   "email_change_failed",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse{
+		Message: "A confirmation link has been sent to the new email address",
+	})
+}
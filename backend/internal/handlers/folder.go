@@ -0,0 +1,192 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"backend/internal/middleware"
+	"backend/internal/models"
+	"backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// FolderHandler handles the folders users organise their projects in.
+type FolderHandler struct {
+	folderService *services.FolderService
+}
+
+func NewFolderHandler(folderService *services.FolderService) *FolderHandler {
+	return &FolderHandler{
+		folderService: folderService,
+	}
+}
+
+// GetFolders handles GET /folders
+func (h *FolderHandler) GetFolders(c *gin.Context) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, models.ErrorResponse{
+			Error:   "unauthorized",
+			Message: "User not authenticated",
+		})
+		return
+	}
+
+	folders, err := h.folderService.ListFolders(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:   "fetch_failed",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse{
+		Message: "Folders retrieved successfully",
+		Data:    folders,
+	})
+}
+
+// CreateFolder handles POST /folders
+func (h *FolderHandler) CreateFolder(c *gin.Context) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, models.ErrorResponse{
+			Error:   "unauthorized",
+			Message: "User not authenticated",
+		})
+		return
+	}
+
+	var req models.CreateFolderRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "invalid_request",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	folder, err := h.folderService.CreateFolder(userID, req)
+	if err != nil {
+		respondFolderError(c, err, "creation_failed")
+		return
+	}
+
+	c.JSON(http.StatusCreated, models.SuccessResponse{
+		Message: "Folder created successfully",
+		Data:    folder,
+	})
+}
+
+// UpdateFolder handles PUT /folders/:id
+func (h *FolderHandler) UpdateFolder(c *gin.Context) {
+	userID, folderID, ok := folderRequest(c)
+	if !ok {
+		return
+	}
+
+	var req models.UpdateFolderRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "invalid_request",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	folder, err := h.folderService.UpdateFolder(folderID, userID, req)
+	if err != nil {
+		respondFolderError(c, err, "update_failed")
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse{
+		Message: "Folder updated successfully",
+		Data:    folder,
+	})
+}
+
+// DeleteFolder handles DELETE /folders/:id
+func (h *FolderHandler) DeleteFolder(c *gin.Context) {
+	userID, folderID, ok := folderRequest(c)
+	if !ok {
+		return
+	}
+
+	if err := h.folderService.DeleteFolder(folderID, userID); err != nil {
+		respondFolderError(c, err, "deletion_failed")
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse{
+		Message: "Folder deleted, its contents moved up a level",
+	})
+}
+
+// MoveProject handles PUT /projects/:id/folder
+func (h *FolderHandler) MoveProject(c *gin.Context) {
+	userID, projectID, ok := projectRequest(c)
+	if !ok {
+		return
+	}
+
+	var req models.MoveProjectRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "invalid_request",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	if err := h.folderService.MoveProject(projectID, userID, req.FolderID); err != nil {
+		respondFolderError(c, err, "move_failed")
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse{
+		Message: "Project moved successfully",
+	})
+}
+
+func folderRequest(c *gin.Context) (int, int, bool) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, models.ErrorResponse{
+			Error:   "unauthorized",
+			Message: "User not authenticated",
+		})
+		return 0, 0, false
+	}
+
+	folderID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "invalid_folder_id",
+			Message: "Folder ID must be a number",
+		})
+		return 0, 0, false
+	}
+
+	return userID, folderID, true
+}
+
+func respondFolderError(c *gin.Context, err error, failCode string) {
+	switch {
+	case errors.Is(err, services.ErrFolderNotFound):
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Error:   "folder_not_found",
+			Message: err.Error(),
+		})
+	case errors.Is(err, services.ErrFolderCycle):
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "invalid_parent",
+			Message: err.Error(),
+		})
+	default:
+		respondProjectError(c, err, failCode)
+	}
+}
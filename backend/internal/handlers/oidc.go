@@ -0,0 +1,217 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"backend/internal/middleware"
+	"backend/internal/models"
+	"backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// OIDCHandler handles login with external OpenID Connect providers.
+type OIDCHandler struct {
+	oidcService *services.OIDCService
+	frontendURL string
+}
+
+func NewOIDCHandler(oidcService *services.OIDCService, frontendURL string) *OIDCHandler {
+	return &OIDCHandler{
+		oidcService: oidcService,
+		frontendURL: strings.TrimRight(frontendURL, "/"),
+	}
+}
+
+// GetProviders handles GET /auth/oidc/providers
+func (h *OIDCHandler) GetProviders(c *gin.Context) {
+	c.JSON(http.StatusOK, models.SuccessResponse{
+		Message: "Providers retrieved successfully",
+		Data:    h.oidcService.ProviderNames(),
+	})
+}
+
+// StartLogin handles GET /auth/oidc/:provider and redirects to the provider
+func (h *OIDCHandler) StartLogin(c *gin.Context) {
+	authURL, err := h.oidcService.StartLogin(c.Request.Context(), c.Param("provider"), 0)
+	if errors.Is(err, services.ErrUnknownProvider) {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Error:   "unknown_provider",
+			Message: err.Error(),
+		})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusBadGateway, models.ErrorResponse{
+			Error:   "provider_unavailable",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.Redirect(http.StatusFound, authURL)
+}
+
+// Callback handles GET /auth/oidc/:provider/callback. The result is passed to
+// the frontend in the URL fragment so tokens never reach server logs.
+func (h *OIDCHandler) Callback(c *gin.Context) {
+	fragment := url.Values{}
+
+	if providerError := c.Query("error"); providerError != "" {
+		fragment.Set("error", providerError)
+		h.redirectToFrontend(c, fragment)
+		return
+	}
+
+	result, err := h.oidcService.HandleCallback(c.Request.Context(), c.Param("provider"), c.Query("state"), c.Query("code"), clientInfo(c))
+	switch {
+	case err == nil:
+	case errors.Is(err, services.ErrOIDCStateInvalid):
+		fragment.Set("error", "invalid_state")
+	case errors.Is(err, services.ErrIdentityConflict):
+		fragment.Set("error", "account_exists")
+	case errors.Is(err, services.ErrIdentityLinkedElsewhere):
+		fragment.Set("error", "identity_in_use")
+	case errors.Is(err, services.ErrUnknownProvider):
+		fragment.Set("error", "unknown_provider")
+	case errors.Is(err, services.ErrAccountSuspended):
+		fragment.Set("error", "account_suspended")
+	default:
+		log.Printf("OIDC callback failed for provider %s: %v", c.Param("provider"), err)
+		fragment.Set("error", "login_failed")
+	}
+	if err != nil {
+		h.redirectToFrontend(c, fragment)
+		return
+	}
+
+	if result.Linked {
+		fragment.Set("linked", c.Param("provider"))
+	} else if result.Login.TwoFactorRequired {
+		fragment.Set("two_factor_required", "true")
+		fragment.Set("challenge_token", result.Login.ChallengeToken)
+	} else {
+		fragment.Set("token", result.Login.Token)
+		fragment.Set("refresh_token", result.Login.RefreshToken)
+		fragment.Set("expires_in", strconv.Itoa(result.Login.ExpiresIn))
+		fragment.Set("user_id", strconv.Itoa(result.Login.UserID))
+	}
+	h.redirectToFrontend(c, fragment)
+}
+
+// StartLink handles POST /user/identities/:provider and returns the URL that
+// links a provider account to the logged in user
+func (h *OIDCHandler) StartLink(c *gin.Context) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, models.ErrorResponse{
+			Error:   "unauthorized",
+			Message: "User not authenticated",
+		})
+		return
+	}
+
+	authURL, err := h.oidcService.StartLogin(c.Request.Context(), c.Param("provider"), userID)
+	if errors.Is(err, services.ErrUnknownProvider) {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Error:   "unknown_provider",
+			Message: err.Error(),
+		})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusBadGateway, models.ErrorResponse{
+			Error:   "provider_unavailable",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse{
+		Message: "Open the authorization URL to link the account",
+		Data:    gin.H{"authorization_url": authURL},
+	})
+}
+
+// GetIdentities handles GET /user/identities
+func (h *OIDCHandler) GetIdentities(c *gin.Context) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, models.ErrorResponse{
+			Error:   "unauthorized",
+			Message: "User not authenticated",
+		})
+		return
+	}
+
+	identities, err := h.oidcService.ListIdentities(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:   "fetch_failed",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse{
+		Message: "Identities retrieved successfully",
+		Data:    identities,
+	})
+}
+
+// DeleteIdentity handles DELETE /user/identities/:id
+func (h *OIDCHandler) DeleteIdentity(c *gin.Context) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, models.ErrorResponse{
+			Error:   "unauthorized",
+			Message: "User not authenticated",
+		})
+		return
+	}
+
+	identityID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "invalid_identity_id",
+			Message: "Identity ID must be a number",
+		})
+		return
+	}
+
+	err = h.oidcService.UnlinkIdentity(userID, identityID)
+	if errors.Is(err, services.ErrIdentityNotFound) {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Error:   "identity_not_found",
+			Message: err.Error(),
+		})
+		return
+	}
+	if errors.Is(err, services.ErrLastLoginMethod) {
+		c.JSON(http.StatusConflict, models.ErrorResponse{
+			Error:   "last_login_method",
+			Message: err.Error(),
+		})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:   "unlink_failed",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse{
+		Message: "Identity unlinked successfully",
+	})
+}
+
+func (h *OIDCHandler) redirectToFrontend(c *gin.Context, fragment url.Values) {
+	c.Redirect(http.StatusFound, h.frontendURL+"/oauth/callback#"+fragment.Encode())
+}
@@ -0,0 +1,136 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"backend/internal/middleware"
+	"backend/internal/models"
+	"backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// PasswordHandler handles password reset and change endpoints.
+type PasswordHandler struct {
+	passwordService *services.PasswordService
+}
+
+func NewPasswordHandler(passwordService *services.PasswordService) *PasswordHandler {
+	return &PasswordHandler{
+		passwordService: passwordService,
+	}
+}
+
+// ForgotPassword handles POST /password/forgot
+func (h *PasswordHandler) ForgotPassword(c *gin.Context) {
+	var req models.ForgotPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "invalid_request",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	if err := h.passwordService.RequestPasswordReset(req); err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:   "reset_request_failed",
+			Message: "Could not process password reset request",
+		})
+		return
+	}
+
+	// Same response whether or not the email is registered
+	c.JSON(http.StatusOK, models.SuccessResponse{
+		Message: "If this email is registered, a password reset link has been sent",
+	})
+}
+
+// ResetPassword handles POST /password/reset
+func (h *PasswordHandler) ResetPassword(c *gin.Context) {
+	var req models.ResetPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "invalid_request",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	err := h.passwordService.ResetPassword(req)
+	if errors.Is(err, services.ErrWeakPassword) {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "weak_password",
+			Message: err.Error(),
+		})
+		return
+	}
+	if errors.Is(err, services.ErrResetTokenInvalid) {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "invalid_reset_token",
+			Message: err.Error(),
+		})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:   "reset_failed",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse{
+		Message: "Password has been reset, please log in again",
+	})
+}
+
+// ChangePassword handles PUT /user/password
+func (h *PasswordHandler) ChangePassword(c *gin.Context) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, models.ErrorResponse{
+			Error:   "unauthorized",
+			Message: "User not authenticated",
+		})
+		return
+	}
+	sessionID, _ := middleware.GetSessionID(c)
+
+	var req models.ChangePasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "invalid_request",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	err := h.passwordService.ChangePassword(userID, sessionID, req)
+	if errors.Is(err, services.ErrInvalidPassword) {
+		c.JSON(http.StatusUnauthorized, models.ErrorResponse{
+			Error:   "invalid_password",
+			Message: err.Error(),
+		})
+		return
+	}
+	if errors.Is(err, services.ErrWeakPassword) {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "weak_password",
+			Message: err.Error(),
+		})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:   "password_change_failed",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse{
+		Message: "Password changed successfully, other sessions have been signed out",
+	})
+}
@@ -0,0 +1,126 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"backend/internal/middleware"
+	"backend/internal/models"
+	"backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// PersonalTokenHandler handles personal access token endpoints.
+type PersonalTokenHandler struct {
+	tokenService *services.PersonalTokenService
+}
+
+func NewPersonalTokenHandler(tokenService *services.PersonalTokenService) *PersonalTokenHandler {
+	return &PersonalTokenHandler{
+		tokenService: tokenService,
+	}
+}
+
+// CreateToken handles POST /user/tokens
+func (h *PersonalTokenHandler) CreateToken(c *gin.Context) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, models.ErrorResponse{
+			Error:   "unauthorized",
+			Message: "User not authenticated",
+		})
+		return
+	}
+
+	var req models.CreatePersonalAccessTokenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "invalid_request",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	token, err := h.tokenService.CreateToken(userID, req)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:   "creation_failed",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, models.SuccessResponse{
+		Message: "Token created successfully. Copy it now, it will not be shown again",
+		Data:    token,
+	})
+}
+
+// GetTokens handles GET /user/tokens
+func (h *PersonalTokenHandler) GetTokens(c *gin.Context) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, models.ErrorResponse{
+			Error:   "unauthorized",
+			Message: "User not authenticated",
+		})
+		return
+	}
+
+	tokens, err := h.tokenService.ListTokens(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:   "fetch_failed",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse{
+		Message: "Tokens retrieved successfully",
+		Data:    tokens,
+	})
+}
+
+// RevokeToken handles DELETE /user/tokens/:id
+func (h *PersonalTokenHandler) RevokeToken(c *gin.Context) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, models.ErrorResponse{
+			Error:   "unauthorized",
+			Message: "User not authenticated",
+		})
+		return
+	}
+
+	tokenID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "invalid_token_id",
+			Message: "Token ID must be a number",
+		})
+		return
+	}
+
+	err = h.tokenService.RevokeToken(userID, tokenID)
+	if errors.Is(err, services.ErrPersonalTokenNotFound) {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Error:   "token_not_found",
+			Message: err.Error(),
+		})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:   "revoke_failed",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse{
+		Message: "Token revoked successfully",
+	})
+}
@@ -2,6 +2,7 @@ package handlers
 
 import (
 	"encoding/json"
+	"errors"
 	"log"
 	"net/http"
 	"strconv"
@@ -46,6 +47,20 @@ func (h *ProjectHandler) CreateProject(c *gin.Context) {
 	}
 
 	project, err := h.projectService.CreateProject(userID, req)
+	if errors.Is(err, services.ErrTemplateNotFound) {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "invalid_template",
+			Message: err.Error(),
+		})
+		return
+	}
+	if errors.Is(err, services.ErrEmailNotVerified) {
+		c.JSON(http.StatusForbidden, models.ErrorResponse{
+			Error:   "email_not_verified",
+			Message: err.Error(),
+		})
+		return
+	}
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
 			Error:   "creation_failed",
@@ -60,44 +75,77 @@ func (h *ProjectHandler) CreateProject(c *gin.Context) {
 	})
 }
 
-// GetProjects handles GET /projects
+// GetProjects handles GET /projects/my, a page of the caller's projects
 func (h *ProjectHandler) GetProjects(c *gin.Context) {
+	var filter models.ProjectFilter
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "invalid_request",
+			Message: err.Error(),
+		})
+		return
+	}
+
 	userID, exists := middleware.GetUserID(c)
 	if !exists {
-		projects, err := h.projectService.GetAllProjects()
+		page, err := h.projectService.GetAllProjects(filter)
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, models.ErrorResponse{
-				Error:   "fetch_failed",
-				Message: err.Error(),
-			})
+			respondListError(c, err)
 			return
 		}
-		c.JSON(http.StatusOK, projects)
+		c.JSON(http.StatusOK, page)
 		return
 	}
 
-	projects, err := h.projectService.GetProjectsByUser(userID)
+	page, err := h.projectService.GetProjectsByUser(userID, filter)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
-			Error:   "fetch_failed",
-			Message: err.Error(),
-		})
+		respondListError(c, err)
 		return
 	}
-	c.JSON(http.StatusOK, projects)
+	c.JSON(http.StatusOK, page)
 }
 
-// GetAllProjects handles GET /projects/all
+// GetAllProjects handles GET /projects, a page of public projects filtered
+// by ?tag= and ?genre=
 func (h *ProjectHandler) GetAllProjects(c *gin.Context) {
-	projects, err := h.projectService.GetAllProjects()
+	var filter models.ProjectFilter
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "invalid_request",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	page, err := h.projectService.GetAllProjects(filter)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
-			Error:   "fetch_failed",
+		respondListError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, page)
+}
+
+// GetFeaturedProjects handles GET /projects/featured, public projects ranked
+// by editor picks and their time-decayed popularity unless ?sort= is given
+func (h *ProjectHandler) GetFeaturedProjects(c *gin.Context) {
+	var filter models.ProjectFilter
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "invalid_request",
 			Message: err.Error(),
 		})
 		return
 	}
-	c.JSON(http.StatusOK, projects)
+	if filter.Sort == "" {
+		filter.Sort = models.ProjectSortFeatured
+	}
+
+	page, err := h.projectService.GetAllProjects(filter)
+	if err != nil {
+		respondListError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, page)
 }
 
 // ✅ GetProject - รองรับทั้ง owner และ public access
@@ -135,6 +183,8 @@ func (h *ProjectHandler) GetProject(c *gin.Context) {
 		})
 		return
 	}
+	viewerID, _ := middleware.GetUserID(c)
+	h.projectService.RecordView(projectID, viewerID, c.ClientIP())
 
 	c.JSON(http.StatusOK, models.SuccessResponse{
 		Message: "Project retrieved successfully",
@@ -177,10 +227,7 @@ func (h *ProjectHandler) UpdateProject(c *gin.Context) {
 	project, err := h.projectService.UpdateProject(projectID, userID, req)
 	if err != nil {
 		log.Println("❌ UpdateProject error:", err)
-		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
-			Error:   "update_failed",
-			Message: err.Error(),
-		})
+		respondProjectError(c, err, "update_failed")
 		return
 	}
 
@@ -190,6 +237,25 @@ func (h *ProjectHandler) UpdateProject(c *gin.Context) {
 	})
 }
 
+// ForkProject handles POST /projects/:id/fork
+func (h *ProjectHandler) ForkProject(c *gin.Context) {
+	userID, projectID, ok := projectRequest(c)
+	if !ok {
+		return
+	}
+
+	project, err := h.projectService.ForkProject(projectID, userID)
+	if err != nil {
+		respondProjectError(c, err, "fork_failed")
+		return
+	}
+
+	c.JSON(http.StatusCreated, models.SuccessResponse{
+		Message: "Project forked successfully",
+		Data:    project,
+	})
+}
+
 // DeleteProject handles DELETE /projects/:id
 func (h *ProjectHandler) DeleteProject(c *gin.Context) {
 	userID, exists := middleware.GetUserID(c)
@@ -212,15 +278,74 @@ func (h *ProjectHandler) DeleteProject(c *gin.Context) {
 	}
 
 	if err := h.projectService.DeleteProject(projectID, userID); err != nil {
+		respondProjectError(c, err, "deletion_failed")
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse{
+		Message: "Project moved to trash",
+	})
+}
+
+// GetTrash handles GET /projects/trash
+func (h *ProjectHandler) GetTrash(c *gin.Context) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, models.ErrorResponse{
+			Error:   "unauthorized",
+			Message: "User not authenticated",
+		})
+		return
+	}
+
+	projects, err := h.projectService.GetTrash(userID)
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
-			Error:   "deletion_failed",
+			Error:   "fetch_failed",
 			Message: err.Error(),
 		})
 		return
 	}
 
 	c.JSON(http.StatusOK, models.SuccessResponse{
-		Message: "Project deleted successfully",
+		Message: "Trash retrieved successfully",
+		Data:    projects,
+	})
+}
+
+// RestoreProject handles POST /projects/:id/restore
+func (h *ProjectHandler) RestoreProject(c *gin.Context) {
+	userID, projectID, ok := projectRequest(c)
+	if !ok {
+		return
+	}
+
+	project, err := h.projectService.RestoreProject(projectID, userID)
+	if err != nil {
+		respondProjectError(c, err, "restore_failed")
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse{
+		Message: "Project restored successfully",
+		Data:    project,
+	})
+}
+
+// PurgeProject handles DELETE /projects/:id/permanent
+func (h *ProjectHandler) PurgeProject(c *gin.Context) {
+	userID, projectID, ok := projectRequest(c)
+	if !ok {
+		return
+	}
+
+	if err := h.projectService.PurgeProject(projectID, userID); err != nil {
+		respondProjectError(c, err, "deletion_failed")
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse{
+		Message: "Project permanently deleted",
 	})
 }
 
@@ -257,10 +382,7 @@ func (h *ProjectHandler) SaveProjectData(c *gin.Context) {
 	}
 
 	if err := h.projectService.SaveProjectData(projectID, userID, requestBody.ProjectData); err != nil {
-		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
-			Error:   "save_failed",
-			Message: err.Error(),
-		})
+		respondProjectError(c, err, "save_failed")
 		return
 	}
 
@@ -318,10 +440,7 @@ func (h *ProjectHandler) AutoSave(c *gin.Context) {
 	}
 
 	if err := h.projectService.SaveProjectData(projectID, userID, jsonData); err != nil {
-		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
-			Error:   "autosave_failed",
-			Message: err.Error(),
-		})
+		respondProjectError(c, err, "autosave_failed")
 		return
 	}
 
@@ -350,6 +469,8 @@ func (h *ProjectHandler) GetPublicProject(c *gin.Context) {
 		})
 		return
 	}
+	viewerID, _ := middleware.GetUserID(c)
+	h.projectService.RecordView(projectID, viewerID, c.ClientIP())
 
 	c.JSON(http.StatusOK, models.SuccessResponse{
 		Message: "Public project retrieved successfully",
@@ -371,4 +492,52 @@ func (h *ProjectHandler) UploadCharacterImage(c *gin.Context) {
 		Error:   "not_implemented",
 		Message: "Character image upload not implemented yet",
 	})
-}
\ No newline at end of file
+}
+
+// respondProjectError maps project access errors to 404 and 403 and anything
+// else to 500 with the given error code.
+func respondProjectError(c *gin.Context, err error, failCode string) {
+	switch {
+	case errors.Is(err, services.ErrProjectNotFound):
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Error:   "project_not_found",
+			Message: err.Error(),
+		})
+	case errors.Is(err, services.ErrProjectForbidden):
+		c.JSON(http.StatusForbidden, models.ErrorResponse{
+			Error:   "forbidden",
+			Message: err.Error(),
+		})
+	case errors.Is(err, services.ErrEmailNotVerified):
+		c.JSON(http.StatusForbidden, models.ErrorResponse{
+			Error:   "email_not_verified",
+			Message: err.Error(),
+		})
+	default:
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:   failCode,
+			Message: err.Error(),
+		})
+	}
+}
+
+// respondListError answers failed project listings
+func respondListError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrInvalidCursor):
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "invalid_cursor",
+			Message: err.Error(),
+		})
+	case errors.Is(err, services.ErrFolderNotFound):
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Error:   "folder_not_found",
+			Message: err.Error(),
+		})
+	default:
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:   "fetch_failed",
+			Message: err.Error(),
+		})
+	}
+}
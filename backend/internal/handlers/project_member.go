@@ -0,0 +1,182 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"backend/internal/middleware"
+	"backend/internal/models"
+	"backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ProjectMemberHandler handles sharing projects with other users.
+type ProjectMemberHandler struct {
+	memberService *services.ProjectMemberService
+}
+
+func NewProjectMemberHandler(memberService *services.ProjectMemberService) *ProjectMemberHandler {
+	return &ProjectMemberHandler{
+		memberService: memberService,
+	}
+}
+
+// GetMembers handles GET /projects/:id/members
+func (h *ProjectMemberHandler) GetMembers(c *gin.Context) {
+	userID, projectID, ok := projectRequest(c)
+	if !ok {
+		return
+	}
+
+	members, err := h.memberService.ListMembers(projectID, userID)
+	if err != nil {
+		respondMemberError(c, err, "fetch_failed")
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse{
+		Message: "Members retrieved successfully",
+		Data:    members,
+	})
+}
+
+// AddMember handles POST /projects/:id/members
+func (h *ProjectMemberHandler) AddMember(c *gin.Context) {
+	userID, projectID, ok := projectRequest(c)
+	if !ok {
+		return
+	}
+
+	var req models.AddMemberRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "invalid_request",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	member, err := h.memberService.AddMember(projectID, userID, req)
+	if err != nil {
+		respondMemberError(c, err, "invite_failed")
+		return
+	}
+
+	c.JSON(http.StatusCreated, models.SuccessResponse{
+		Message: "Member added successfully",
+		Data:    member,
+	})
+}
+
+// UpdateMember handles PUT /projects/:id/members/:userId
+func (h *ProjectMemberHandler) UpdateMember(c *gin.Context) {
+	userID, projectID, ok := projectRequest(c)
+	if !ok {
+		return
+	}
+	memberID, ok := memberParam(c)
+	if !ok {
+		return
+	}
+
+	var req models.UpdateMemberRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "invalid_request",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	member, err := h.memberService.UpdateMemberRole(projectID, userID, memberID, req.Role)
+	if err != nil {
+		respondMemberError(c, err, "update_failed")
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse{
+		Message: "Member updated successfully",
+		Data:    member,
+	})
+}
+
+// RemoveMember handles DELETE /projects/:id/members/:userId
+func (h *ProjectMemberHandler) RemoveMember(c *gin.Context) {
+	userID, projectID, ok := projectRequest(c)
+	if !ok {
+		return
+	}
+	memberID, ok := memberParam(c)
+	if !ok {
+		return
+	}
+
+	if err := h.memberService.RemoveMember(projectID, userID, memberID); err != nil {
+		respondMemberError(c, err, "removal_failed")
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse{
+		Message: "Member removed successfully",
+	})
+}
+
+// projectRequest reads the authenticated user and the :id parameter. It
+// writes the error response itself and reports whether to continue.
+func projectRequest(c *gin.Context) (int, int, bool) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, models.ErrorResponse{
+			Error:   "unauthorized",
+			Message: "User not authenticated",
+		})
+		return 0, 0, false
+	}
+
+	projectID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "invalid_project_id",
+			Message: "Project ID must be a number",
+		})
+		return 0, 0, false
+	}
+
+	return userID, projectID, true
+}
+
+func memberParam(c *gin.Context) (int, bool) {
+	memberID, err := strconv.Atoi(c.Param("userId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "invalid_user_id",
+			Message: "User ID must be a number",
+		})
+		return 0, false
+	}
+	return memberID, true
+}
+
+func respondMemberError(c *gin.Context, err error, failCode string) {
+	switch {
+	case errors.Is(err, services.ErrUserNotFound), errors.Is(err, services.ErrMemberNotFound):
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Error:   "member_not_found",
+			Message: err.Error(),
+		})
+	case errors.Is(err, services.ErrAlreadyMember):
+		c.JSON(http.StatusConflict, models.ErrorResponse{
+			Error:   "already_member",
+			Message: err.Error(),
+		})
+	case errors.Is(err, services.ErrProjectCreator):
+		c.JSON(http.StatusForbidden, models.ErrorResponse{
+			Error:   "forbidden",
+			Message: err.Error(),
+		})
+	default:
+		respondProjectError(c, err, failCode)
+	}
+}
@@ -0,0 +1,146 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"backend/internal/models"
+	"backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ShareLinkPasswordHeader carries the password of a protected share link.
+const ShareLinkPasswordHeader = "X-Share-Password"
+
+// ShareLinkHandler handles secret read-only project links.
+type ShareLinkHandler struct {
+	shareService *services.ShareLinkService
+}
+
+func NewShareLinkHandler(shareService *services.ShareLinkService) *ShareLinkHandler {
+	return &ShareLinkHandler{
+		shareService: shareService,
+	}
+}
+
+// GetShareLinks handles GET /projects/:id/share-links
+func (h *ShareLinkHandler) GetShareLinks(c *gin.Context) {
+	userID, projectID, ok := projectRequest(c)
+	if !ok {
+		return
+	}
+
+	links, err := h.shareService.ListLinks(projectID, userID)
+	if err != nil {
+		respondProjectError(c, err, "fetch_failed")
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse{
+		Message: "Share links retrieved successfully",
+		Data:    links,
+	})
+}
+
+// CreateShareLink handles POST /projects/:id/share-links
+func (h *ShareLinkHandler) CreateShareLink(c *gin.Context) {
+	userID, projectID, ok := projectRequest(c)
+	if !ok {
+		return
+	}
+
+	var req models.CreateShareLinkRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "invalid_request",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	link, err := h.shareService.CreateLink(projectID, userID, req)
+	if err != nil {
+		respondProjectError(c, err, "creation_failed")
+		return
+	}
+
+	c.JSON(http.StatusCreated, models.SuccessResponse{
+		Message: "Share link created successfully. Copy it now, it will not be shown again",
+		Data:    link,
+	})
+}
+
+// RevokeShareLink handles DELETE /projects/:id/share-links/:linkId
+func (h *ShareLinkHandler) RevokeShareLink(c *gin.Context) {
+	userID, projectID, ok := projectRequest(c)
+	if !ok {
+		return
+	}
+
+	linkID, err := strconv.Atoi(c.Param("linkId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "invalid_link_id",
+			Message: "Link ID must be a number",
+		})
+		return
+	}
+
+	err = h.shareService.RevokeLink(projectID, userID, linkID)
+	if errors.Is(err, services.ErrShareLinkNotFound) {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Error:   "link_not_found",
+			Message: err.Error(),
+		})
+		return
+	}
+	if err != nil {
+		respondProjectError(c, err, "revoke_failed")
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse{
+		Message: "Share link revoked successfully",
+	})
+}
+
+// GetSharedProject handles GET /shared/:token. The password of a protected
+// link is sent in the X-Share-Password header.
+func (h *ShareLinkHandler) GetSharedProject(c *gin.Context) {
+	project, err := h.shareService.OpenLink(c.Param("token"), c.GetHeader(ShareLinkPasswordHeader))
+	if errors.Is(err, services.ErrShareLinkInvalid) {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Error:   "link_not_found",
+			Message: err.Error(),
+		})
+		return
+	}
+	if errors.Is(err, services.ErrShareLinkPasswordRequired) {
+		c.JSON(http.StatusUnauthorized, models.ErrorResponse{
+			Error:   "password_required",
+			Message: err.Error(),
+		})
+		return
+	}
+	if errors.Is(err, services.ErrShareLinkPasswordInvalid) {
+		c.JSON(http.StatusUnauthorized, models.ErrorResponse{
+			Error:   "invalid_password",
+			Message: err.Error(),
+		})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:   "fetch_failed",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse{
+		Message: "Shared project retrieved successfully",
+		Data:    project,
+	})
+}
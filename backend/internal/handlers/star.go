@@ -0,0 +1,88 @@
+package handlers
+
+import (
+	"net/http"
+
+	"backend/internal/middleware"
+	"backend/internal/models"
+	"backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type StarHandler struct {
+	starService *services.StarService
+}
+
+func NewStarHandler(starService *services.StarService) *StarHandler {
+	return &StarHandler{
+		starService: starService,
+	}
+}
+
+// StarProject handles PUT /projects/:id/star
+func (h *StarHandler) StarProject(c *gin.Context) {
+	userID, projectID, ok := projectRequest(c)
+	if !ok {
+		return
+	}
+
+	status, err := h.starService.Star(projectID, userID)
+	if err != nil {
+		respondProjectError(c, err, "star_failed")
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse{
+		Message: "Project starred",
+		Data:    status,
+	})
+}
+
+// UnstarProject handles DELETE /projects/:id/star
+func (h *StarHandler) UnstarProject(c *gin.Context) {
+	userID, projectID, ok := projectRequest(c)
+	if !ok {
+		return
+	}
+
+	status, err := h.starService.Unstar(projectID, userID)
+	if err != nil {
+		respondProjectError(c, err, "unstar_failed")
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse{
+		Message: "Project unstarred",
+		Data:    status,
+	})
+}
+
+// GetStarredProjects handles GET /projects/starred, a page of the caller's
+// starred projects
+func (h *StarHandler) GetStarredProjects(c *gin.Context) {
+	var filter models.ProjectFilter
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "invalid_request",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, models.ErrorResponse{
+			Error:   "unauthorized",
+			Message: "User not authenticated",
+		})
+		return
+	}
+
+	page, err := h.starService.GetStarredProjects(userID, filter)
+	if err != nil {
+		respondListError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, page)
+}
@@ -0,0 +1,100 @@
+package handlers
+
+import (
+	"net/http"
+
+	"backend/internal/models"
+	"backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// TagHandler handles project tags, genres and the tag cloud.
+type TagHandler struct {
+	tagService *services.TagService
+}
+
+func NewTagHandler(tagService *services.TagService) *TagHandler {
+	return &TagHandler{
+		tagService: tagService,
+	}
+}
+
+// GetGenres handles GET /genres
+func (h *TagHandler) GetGenres(c *gin.Context) {
+	c.JSON(http.StatusOK, models.SuccessResponse{
+		Message: "Genres retrieved successfully",
+		Data:    models.Genres,
+	})
+}
+
+// GetTagCloud handles GET /tags
+func (h *TagHandler) GetTagCloud(c *gin.Context) {
+	var filter models.TagCloudFilter
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "invalid_request",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	tags, err := h.tagService.TagCloud(filter.Limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:   "fetch_failed",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse{
+		Message: "Tags retrieved successfully",
+		Data:    tags,
+	})
+}
+
+// SetTags handles PUT /projects/:id/tags
+func (h *TagHandler) SetTags(c *gin.Context) {
+	userID, projectID, ok := projectRequest(c)
+	if !ok {
+		return
+	}
+
+	var req models.SetTagsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "invalid_request",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	tags, err := h.tagService.SetTags(projectID, userID, req.Tags)
+	if err != nil {
+		respondProjectError(c, err, "update_failed")
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse{
+		Message: "Tags updated successfully",
+		Data:    tags,
+	})
+}
+
+// RemoveTag handles DELETE /projects/:id/tags/:tag
+func (h *TagHandler) RemoveTag(c *gin.Context) {
+	userID, projectID, ok := projectRequest(c)
+	if !ok {
+		return
+	}
+
+	if err := h.tagService.RemoveTag(projectID, userID, c.Param("tag")); err != nil {
+		respondProjectError(c, err, "update_failed")
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse{
+		Message: "Tag removed successfully",
+	})
+}
@@ -0,0 +1,128 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"backend/internal/middleware"
+	"backend/internal/models"
+	"backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// TemplateHandler handles the project template library.
+type TemplateHandler struct {
+	templateService *services.TemplateService
+}
+
+func NewTemplateHandler(templateService *services.TemplateService) *TemplateHandler {
+	return &TemplateHandler{
+		templateService: templateService,
+	}
+}
+
+// GetTemplates handles GET /templates. Logged in users also get their own templates.
+func (h *TemplateHandler) GetTemplates(c *gin.Context) {
+	userID, _ := middleware.GetUserID(c)
+
+	templates, err := h.templateService.ListTemplates(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:   "fetch_failed",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse{
+		Message: "Templates retrieved successfully",
+		Data:    templates,
+	})
+}
+
+// GetTemplate handles GET /templates/:id
+func (h *TemplateHandler) GetTemplate(c *gin.Context) {
+	userID, _ := middleware.GetUserID(c)
+
+	template, err := h.templateService.GetTemplate(c.Param("id"), userID)
+	if errors.Is(err, services.ErrTemplateNotFound) {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Error:   "template_not_found",
+			Message: err.Error(),
+		})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:   "fetch_failed",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse{
+		Message: "Template retrieved successfully",
+		Data:    template,
+	})
+}
+
+// SaveTemplate handles POST /projects/:id/template
+func (h *TemplateHandler) SaveTemplate(c *gin.Context) {
+	userID, projectID, ok := projectRequest(c)
+	if !ok {
+		return
+	}
+
+	var req models.SaveTemplateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "invalid_request",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	template, err := h.templateService.SaveTemplate(projectID, userID, req)
+	if err != nil {
+		respondProjectError(c, err, "save_failed")
+		return
+	}
+
+	c.JSON(http.StatusCreated, models.SuccessResponse{
+		Message: "Template saved successfully",
+		Data:    template,
+	})
+}
+
+// DeleteTemplate handles DELETE /templates/:id
+func (h *TemplateHandler) DeleteTemplate(c *gin.Context) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, models.ErrorResponse{
+			Error:   "unauthorized",
+			Message: "User not authenticated",
+		})
+		return
+	}
+
+	err := h.templateService.DeleteTemplate(c.Param("id"), userID)
+	if errors.Is(err, services.ErrTemplateNotFound) {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Error:   "template_not_found",
+			Message: err.Error(),
+		})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:   "deletion_failed",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse{
+		Message: "Template deleted successfully",
+	})
+}
@@ -0,0 +1,246 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"backend/internal/middleware"
+	"backend/internal/models"
+	"backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// TwoFactorHandler handles TOTP enrolment and the second login step.
+type TwoFactorHandler struct {
+	authService      *services.AuthService
+	twoFactorService *services.TwoFactorService
+}
+
+func NewTwoFactorHandler(authService *services.AuthService, twoFactorService *services.TwoFactorService) *TwoFactorHandler {
+	return &TwoFactorHandler{
+		authService:      authService,
+		twoFactorService: twoFactorService,
+	}
+}
+
+// Login handles POST /login/2fa
+func (h *TwoFactorHandler) Login(c *gin.Context) {
+	var req models.TwoFactorLoginRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "invalid_request",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	loginResponse, err := h.authService.CompleteTwoFactorLogin(req, clientInfo(c))
+	if respondThrottled(c, err) {
+		return
+	}
+	if errors.Is(err, services.ErrChallengeInvalid) {
+		c.JSON(http.StatusUnauthorized, models.ErrorResponse{
+			Error:   "invalid_challenge",
+			Message: err.Error(),
+		})
+		return
+	}
+	if errors.Is(err, services.ErrInvalidTwoFactorCode) || errors.Is(err, services.ErrTwoFactorNotEnabled) {
+		c.JSON(http.StatusUnauthorized, models.ErrorResponse{
+			Error:   "invalid_code",
+			Message: services.ErrInvalidTwoFactorCode.Error(),
+		})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:   "login_failed",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, loginResponse)
+}
+
+// Setup handles POST /user/2fa/setup
+func (h *TwoFactorHandler) Setup(c *gin.Context) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, models.ErrorResponse{
+			Error:   "unauthorized",
+			Message: "User not authenticated",
+		})
+		return
+	}
+
+	setup, err := h.twoFactorService.Setup(userID)
+	if errors.Is(err, services.ErrTwoFactorAlreadyEnabled) {
+		c.JSON(http.StatusConflict, models.ErrorResponse{
+			Error:   "already_enabled",
+			Message: err.Error(),
+		})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:   "setup_failed",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse{
+		Message: "Scan the code with your authenticator app and confirm with a code",
+		Data:    setup,
+	})
+}
+
+// Confirm handles POST /user/2fa/confirm
+func (h *TwoFactorHandler) Confirm(c *gin.Context) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, models.ErrorResponse{
+			Error:   "unauthorized",
+			Message: "User not authenticated",
+		})
+		return
+	}
+
+	var req models.TwoFactorCodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "invalid_request",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	codes, err := h.twoFactorService.Confirm(userID, req.Code)
+	if errors.Is(err, services.ErrTwoFactorAlreadyEnabled) {
+		c.JSON(http.StatusConflict, models.ErrorResponse{
+			Error:   "already_enabled",
+			Message: err.Error(),
+		})
+		return
+	}
+	if errors.Is(err, services.ErrTwoFactorNotPending) || errors.Is(err, services.ErrInvalidTwoFactorCode) {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "invalid_code",
+			Message: err.Error(),
+		})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:   "confirm_failed",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse{
+		Message: "Two-factor authentication enabled, store your recovery codes safely",
+		Data:    models.RecoveryCodesResponse{RecoveryCodes: codes},
+	})
+}
+
+// Disable handles POST /user/2fa/disable
+func (h *TwoFactorHandler) Disable(c *gin.Context) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, models.ErrorResponse{
+			Error:   "unauthorized",
+			Message: "User not authenticated",
+		})
+		return
+	}
+
+	var req models.DisableTwoFactorRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "invalid_request",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	err := h.twoFactorService.Disable(userID, req)
+	if errors.Is(err, services.ErrInvalidPassword) {
+		c.JSON(http.StatusUnauthorized, models.ErrorResponse{
+			Error:   "invalid_password",
+			Message: err.Error(),
+		})
+		return
+	}
+	if errors.Is(err, services.ErrTwoFactorNotEnabled) || errors.Is(err, services.ErrInvalidTwoFactorCode) {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "invalid_code",
+			Message: err.Error(),
+		})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:   "disable_failed",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse{
+		Message: "Two-factor authentication disabled",
+	})
+}
+
+// RegenerateRecoveryCodes handles POST /user/2fa/recovery-codes
+func (h *TwoFactorHandler) RegenerateRecoveryCodes(c *gin.Context) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, models.ErrorResponse{
+			Error:   "unauthorized",
+			Message: "User not authenticated",
+		})
+		return
+	}
+
+	var req models.TwoFactorCodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "invalid_request",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	// Require a current code so a stolen session alone cannot mint new codes
+	if err := h.twoFactorService.Verify(userID, req.Code, ""); err != nil {
+		if errors.Is(err, services.ErrTwoFactorNotEnabled) || errors.Is(err, services.ErrInvalidTwoFactorCode) {
+			c.JSON(http.StatusBadRequest, models.ErrorResponse{
+				Error:   "invalid_code",
+				Message: err.Error(),
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:   "regenerate_failed",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	codes, err := h.twoFactorService.RegenerateRecoveryCodes(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:   "regenerate_failed",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse{
+		Message: "Recovery codes regenerated",
+		Data:    models.RecoveryCodesResponse{RecoveryCodes: codes},
+	})
+}
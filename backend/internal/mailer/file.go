@@ -0,0 +1,54 @@
+package mailer
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// FileMailer writes every message as an .eml file into a directory. It is
+// meant for local development and tests where no SMTP server is available.
+type FileMailer struct {
+	from string
+	dir  string
+	mu   sync.Mutex
+	seq  int
+}
+
+func NewFileMailer(from, dir string) *FileMailer {
+	return &FileMailer{from: from, dir: dir}
+}
+
+func (m *FileMailer) Send(msg Message) error {
+	if err := os.MkdirAll(m.dir, 0755); err != nil {
+		return fmt.Errorf("error creating mail directory: %w", err)
+	}
+
+	m.mu.Lock()
+	m.seq++
+	name := fmt.Sprintf("%s-%03d.eml", time.Now().Format("20060102-150405"), m.seq)
+	m.mu.Unlock()
+
+	if err := os.WriteFile(filepath.Join(m.dir, name), buildMessage(m.from, msg), 0644); err != nil {
+		return fmt.Errorf("error writing mail file: %w", err)
+	}
+
+	return nil
+}
+
+// LogMailer prints messages to the application log instead of sending them.
+type LogMailer struct {
+	from string
+}
+
+func NewLogMailer(from string) *LogMailer {
+	return &LogMailer{from: from}
+}
+
+func (m *LogMailer) Send(msg Message) error {
+	log.Printf("[MAIL] From: %s To: %s Subject: %s\n%s", m.from, msg.To, msg.Subject, msg.Body)
+	return nil
+}
@@ -0,0 +1,33 @@
+package mailer
+
+import (
+	"fmt"
+
+	"backend/internal/config"
+)
+
+// Message is a plain-text email.
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Mailer sends transactional emails such as password reset links.
+type Mailer interface {
+	Send(msg Message) error
+}
+
+// New returns the Mailer selected by cfg.Driver ("smtp", "file" or "log").
+func New(cfg config.MailConfig) (Mailer, error) {
+	switch cfg.Driver {
+	case "smtp":
+		return NewSMTPMailer(cfg)
+	case "file":
+		return NewFileMailer(cfg.From, cfg.FileDir), nil
+	case "log", "":
+		return NewLogMailer(cfg.From), nil
+	default:
+		return nil, fmt.Errorf("unknown mail driver: %s", cfg.Driver)
+	}
+}
@@ -0,0 +1,62 @@
+package mailer
+
+import (
+	"fmt"
+	"net/mail"
+	"net/smtp"
+	"strings"
+
+	"backend/internal/config"
+)
+
+// SMTPMailer delivers messages through an SMTP server.
+type SMTPMailer struct {
+	from     string // the From header, possibly with a display name
+	sender   string // the bare address used as the envelope sender
+	addr     string
+	host     string
+	user     string
+	password string
+}
+
+func NewSMTPMailer(cfg config.MailConfig) (*SMTPMailer, error) {
+	from, err := mail.ParseAddress(cfg.From)
+	if err != nil {
+		return nil, fmt.Errorf("invalid MAIL_FROM address: %w", err)
+	}
+
+	return &SMTPMailer{
+		from:     cfg.From,
+		sender:   from.Address,
+		addr:     cfg.SMTPHost + ":" + cfg.SMTPPort,
+		host:     cfg.SMTPHost,
+		user:     cfg.SMTPUser,
+		password: cfg.SMTPPassword,
+	}, nil
+}
+
+func (m *SMTPMailer) Send(msg Message) error {
+	var auth smtp.Auth
+	if m.user != "" {
+		auth = smtp.PlainAuth("", m.user, m.password, m.host)
+	}
+
+	if err := smtp.SendMail(m.addr, auth, m.sender, []string{msg.To}, buildMessage(m.from, msg)); err != nil {
+		return fmt.Errorf("error sending mail: %w", err)
+	}
+
+	return nil
+}
+
+// buildMessage renders msg as an RFC 5322 message.
+func buildMessage(from string, msg Message) []byte {
+	var b strings.Builder
+	b.WriteString("From: " + from + "\r\n")
+	b.WriteString("To: " + msg.To + "\r\n")
+	b.WriteString("Subject: " + msg.Subject + "\r\n")
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	b.WriteString("\r\n")
+	b.WriteString(msg.Body)
+	return []byte(b.String())
+}
@@ -1,6 +1,7 @@
 package middleware
 
 import (
+    "errors"
     "net/http"
     "backend/internal/models"
     "backend/internal/services"
@@ -9,7 +10,7 @@ import (
     "github.com/gin-gonic/gin"
 )
 
-func AuthMiddleware(authService *services.AuthService) gin.HandlerFunc {
+func AuthMiddleware(authService *services.AuthService, tokenService *services.PersonalTokenService) gin.HandlerFunc {
     return func(c *gin.Context) {
         // Get token from Authorization header
         authHeader := c.GetHeader("Authorization")
@@ -35,8 +36,16 @@ func AuthMiddleware(authService *services.AuthService) gin.HandlerFunc {
 
         token := tokenParts[1]
 
-        // Validate token
-        userID, err := authService.ValidateToken(token)
+        // Validate token (session JWT or personal access token)
+        err := authenticate(c, token, authService, tokenService)
+        if errors.Is(err, services.ErrAccountSuspended) {
+            c.JSON(http.StatusForbidden, models.ErrorResponse{
+                Error:   "account_suspended",
+                Message: err.Error(),
+            })
+            c.Abort()
+            return
+        }
         if err != nil {
             c.JSON(http.StatusUnauthorized, models.ErrorResponse{
                 Error:   "invalid_token",
@@ -46,24 +55,19 @@ func AuthMiddleware(authService *services.AuthService) gin.HandlerFunc {
             return
         }
 
-        // Store user ID in context for use in handlers
-        c.Set("user_id", userID)
         c.Next()
     }
 }
 
 // ✅ Optional middleware - allows both authenticated and unauthenticated requests
-func OptionalAuthMiddleware(authService *services.AuthService) gin.HandlerFunc {
+func OptionalAuthMiddleware(authService *services.AuthService, tokenService *services.PersonalTokenService) gin.HandlerFunc {
     return func(c *gin.Context) {
         authHeader := c.GetHeader("Authorization")
         
         if authHeader != "" {
             tokenParts := strings.Split(authHeader, " ")
             if len(tokenParts) == 2 && tokenParts[0] == "Bearer" {
-                token := tokenParts[1]
-                if userID, err := authService.ValidateToken(token); err == nil {
-                    c.Set("user_id", userID)
-                }
+                authenticate(c, tokenParts[1], authService, tokenService)
             }
         }
         
@@ -71,6 +75,93 @@ func OptionalAuthMiddleware(authService *services.AuthService) gin.HandlerFunc {
     }
 }
 
+// authenticate validates the token and stores the caller in the context.
+// Session JWTs set user_id and session_id; personal access tokens set
+// user_id and token_scopes.
+func authenticate(c *gin.Context, token string, authService *services.AuthService, tokenService *services.PersonalTokenService) error {
+    if strings.HasPrefix(token, services.PersonalTokenPrefix) {
+        userID, scopes, err := tokenService.ValidateToken(token)
+        if err != nil {
+            return err
+        }
+        c.Set("user_id", userID)
+        c.Set("token_scopes", scopes)
+        return nil
+    }
+
+    claims, err := authService.ValidateToken(token)
+    if err != nil {
+        return err
+    }
+    c.Set("user_id", claims.UserID)
+    c.Set("session_id", claims.SessionID)
+    c.Set("user_role", claims.Role)
+    return nil
+}
+
+// RequireRole only allows users with one of the given roles. Personal access
+// tokens never carry a role, so this also requires a login session.
+func RequireRole(roles ...string) gin.HandlerFunc {
+    return func(c *gin.Context) {
+        role, _ := GetUserRole(c)
+        for _, allowed := range roles {
+            if role == allowed {
+                c.Next()
+                return
+            }
+        }
+
+        c.JSON(http.StatusForbidden, models.ErrorResponse{
+            Error:   "forbidden",
+            Message: "You do not have permission to perform this action",
+        })
+        c.Abort()
+    }
+}
+
+// RequireScope rejects personal access tokens that were not granted the
+// scope. Session tokens carry full account access and always pass.
+func RequireScope(scope string) gin.HandlerFunc {
+    return func(c *gin.Context) {
+        if scopes, isPersonalToken := GetTokenScopes(c); isPersonalToken {
+            granted := false
+            for _, s := range scopes {
+                if s == scope {
+                    granted = true
+                    break
+                }
+            }
+            if !granted {
+                c.JSON(http.StatusForbidden, models.ErrorResponse{
+                    Error:   "insufficient_scope",
+                    Message: "Token is missing the required scope: " + scope,
+                })
+                c.Abort()
+                return
+            }
+        }
+
+        c.Next()
+    }
+}
+
+// RequireSession only allows requests made with an interactive login session,
+// keeping account security settings out of reach of personal access tokens.
+func RequireSession() gin.HandlerFunc {
+    return func(c *gin.Context) {
+        if _, isPersonalToken := GetTokenScopes(c); isPersonalToken {
+            c.JSON(http.StatusForbidden, models.ErrorResponse{
+                Error:   "session_required",
+                Message: "This action requires logging in, personal access tokens are not allowed",
+            })
+            c.Abort()
+            return
+        }
+
+        c.Next()
+    }
+}
+
 // Helper function to get user ID from context
 func GetUserID(c *gin.Context) (int, bool) {
     userID, exists := c.Get("user_id")
@@ -80,4 +171,39 @@ func GetUserID(c *gin.Context) (int, bool) {
     
     id, ok := userID.(int)
     return id, ok
-}
\ No newline at end of file
+}
+
+// Helper function to get the current session ID from context
+func GetSessionID(c *gin.Context) (int, bool) {
+    sessionID, exists := c.Get("session_id")
+    if !exists {
+        return 0, false
+    }
+
+    id, ok := sessionID.(int)
+    return id, ok
+}
+
+// Helper function to get the scopes of a personal access token from context.
+// The second value is false for requests authenticated with a session.
+func GetTokenScopes(c *gin.Context) ([]string, bool) {
+    scopes, exists := c.Get("token_scopes")
+    if !exists {
+        return nil, false
+    }
+
+    list, ok := scopes.([]string)
+    return list, ok
+}
+
+// Helper function to get the user's role from context. It is only set for
+// requests authenticated with a login session.
+func GetUserRole(c *gin.Context) (string, bool) {
+    role, exists := c.Get("user_role")
+    if !exists {
+        return "", false
+    }
+
+    roleStr, ok := role.(string)
+    return roleStr, ok
+}
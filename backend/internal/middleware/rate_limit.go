@@ -0,0 +1,71 @@
+package middleware
+
+import (
+    "net/http"
+    "strconv"
+    "sync"
+    "time"
+
+    "backend/internal/models"
+
+    "github.com/gin-gonic/gin"
+)
+
+// rateLimitWindow counts the requests of one client in the current window
+type rateLimitWindow struct {
+    start time.Time
+    count int
+}
+
+// RateLimitMiddleware allows each client IP at most limit requests per
+// window. Counters are kept in memory, so every instance limits on its own.
+func RateLimitMiddleware(limit int, window time.Duration) gin.HandlerFunc {
+    var (
+        mu        sync.Mutex
+        clients   = make(map[string]*rateLimitWindow)
+        lastSweep = time.Now()
+    )
+
+    return func(c *gin.Context) {
+        if limit <= 0 {
+            c.Next()
+            return
+        }
+
+        now := time.Now()
+        clientIP := c.ClientIP()
+
+        mu.Lock()
+        // Drop expired windows now and then so the map does not grow forever
+        if now.Sub(lastSweep) > window {
+            for ip, w := range clients {
+                if now.Sub(w.start) >= window {
+                    delete(clients, ip)
+                }
+            }
+            lastSweep = now
+        }
+
+        w, exists := clients[clientIP]
+        if !exists || now.Sub(w.start) >= window {
+            w = &rateLimitWindow{start: now}
+            clients[clientIP] = w
+        }
+        w.count++
+        allowed := w.count <= limit
+        retryAfter := w.start.Add(window).Sub(now)
+        mu.Unlock()
+
+        if !allowed {
+            c.Header("Retry-After", strconv.Itoa(int(retryAfter.Seconds())+1))
+            c.JSON(http.StatusTooManyRequests, models.ErrorResponse{
+                Error:   "rate_limited",
+                Message: "Too many requests, please slow down",
+            })
+            c.Abort()
+            return
+        }
+
+        c.Next()
+    }
+}
@@ -0,0 +1,67 @@
+package models
+
+import "time"
+
+// User roles, from least to most privileged
+const (
+    RoleUser      = "user"
+    RoleModerator = "moderator"
+    RoleAdmin     = "admin"
+)
+
+// AdminUser is the view of an account shown to moderators
+type AdminUser struct {
+    ID               int        `json:"id"`
+    Username         string     `json:"username"`
+    UserName         string     `json:"user_name"`
+    Email            string     `json:"email"`
+    Role             string     `json:"role"`
+    EmailVerified    bool       `json:"email_verified"`
+    SuspendedAt      *time.Time `json:"suspended_at"`
+    SuspensionReason *string    `json:"suspension_reason"`
+    ProjectCount     int        `json:"project_count"`
+    CreatedAt        time.Time  `json:"created_at"`
+}
+
+type AdminUserFilter struct {
+    Query     string `form:"q"` // matches username, display name or email
+    Role      string `form:"role" binding:"omitempty,oneof=user moderator admin"`
+    Suspended *bool  `form:"suspended"`
+    Limit     int    `form:"limit" binding:"omitempty,min=1,max=100"`
+    Offset    int    `form:"offset" binding:"omitempty,min=0"`
+}
+
+type AdminUserList struct {
+    Users []AdminUser `json:"users"`
+    Total int         `json:"total"`
+}
+
+// AdminProject is the view of a project shown to moderators
+type AdminProject struct {
+    ProjectListItem
+    UserID          int        `json:"user_id"`
+    UnpublishedAt   *time.Time `json:"unpublished_at"`
+    UnpublishReason *string    `json:"unpublish_reason"`
+    PickedAt        *time.Time `json:"picked_at"`
+}
+
+type AdminProjectFilter struct {
+    Query       string `form:"q"` // matches title or author
+    UserID      int    `form:"user_id"`
+    Unpublished *bool  `form:"unpublished"`
+    Limit       int    `form:"limit" binding:"omitempty,min=1,max=100"`
+    Offset      int    `form:"offset" binding:"omitempty,min=0"`
+}
+
+type AdminProjectList struct {
+    Projects []AdminProject `json:"projects"`
+    Total    int            `json:"total"`
+}
+
+type ModerationRequest struct {
+    Reason string `json:"reason" binding:"max=255"`
+}
+
+type UpdateRoleRequest struct {
+    Role string `json:"role" binding:"required,oneof=user moderator admin"`
+}
@@ -0,0 +1,41 @@
+package models
+
+import "time"
+
+// Comment is a comment on a project, possibly anchored to one of its
+// diagram elements. Deleted comments that still have replies are kept as
+// placeholders without a body or author.
+type Comment struct {
+    ID             int             `json:"id"`
+    ProjectID      int             `json:"project_id"`
+    ParentID       *int            `json:"parent_id"`
+    ElementID      *string         `json:"element_id"`
+    Element        *CommentElement `json:"element,omitempty"`
+    Body           string          `json:"body"`
+    UserID         int             `json:"user_id,omitempty"`
+    AuthorName     string          `json:"authorName,omitempty"`
+    AuthorUsername string          `json:"authorUsername,omitempty"`
+    Deleted        bool            `json:"deleted"`
+    EditedAt       *time.Time      `json:"edited_at"`
+    CreatedAt      time.Time       `json:"created_at"`
+    Replies        []Comment       `json:"replies"`
+}
+
+// CommentElement describes the element a comment is anchored to. Missing is
+// set once the element has been removed from the diagram; the comment then
+// reads as a comment on the whole project.
+type CommentElement struct {
+    Type    string `json:"type,omitempty"` // character or relationship
+    Label   string `json:"label,omitempty"`
+    Missing bool   `json:"missing"`
+}
+
+type CreateCommentRequest struct {
+    Body      string  `json:"body" binding:"required,max=5000"`
+    ParentID  *int    `json:"parent_id"`
+    ElementID *string `json:"element_id" binding:"omitempty,max=64"` // replies use their parent's element
+}
+
+type UpdateCommentRequest struct {
+    Body string `json:"body" binding:"required,max=5000"`
+}
@@ -0,0 +1,34 @@
+package models
+
+import "time"
+
+// Folder organises a user's own view of their projects. Folders can be
+// nested; every user files shared projects independently.
+type Folder struct {
+    ID           int       `json:"id"`
+    Name         string    `json:"name"`
+    ParentID     *int      `json:"parent_id"`
+    ProjectCount int       `json:"project_count"`
+    CreatedAt    time.Time `json:"created_at"`
+    UpdatedAt    time.Time `json:"updated_at"`
+}
+
+type CreateFolderRequest struct {
+    Name     string `json:"name" binding:"required,max=100"`
+    ParentID *int   `json:"parent_id"`
+}
+
+// UpdateFolderRequest renames a folder and/or moves it. MoveToRoot moves it
+// to the top level, since a null parent_id cannot be told apart from an
+// omitted one.
+type UpdateFolderRequest struct {
+    Name       *string `json:"name" binding:"omitempty,min=1,max=100"`
+    ParentID   *int    `json:"parent_id"`
+    MoveToRoot bool    `json:"move_to_root"`
+}
+
+// MoveProjectRequest files a project in a folder, or takes it out of any
+// folder when FolderID is null.
+type MoveProjectRequest struct {
+    FolderID *int `json:"folder_id"`
+}
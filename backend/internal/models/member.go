@@ -0,0 +1,32 @@
+package models
+
+import "time"
+
+// Project member roles, from least to most privileged. Viewers can open a
+// private project, editors can also change it, and owners can additionally
+// manage members, change visibility and delete it. The creator of a project
+// is always an owner.
+const (
+    ProjectRoleViewer = "viewer"
+    ProjectRoleEditor = "editor"
+    ProjectRoleOwner  = "owner"
+)
+
+type ProjectMember struct {
+    UserID       int       `json:"user_id"`
+    Username     string    `json:"username"`
+    UserName     string    `json:"user_name"`
+    ProfileImage *string   `json:"profile_image"`
+    Role         string    `json:"role"`
+    Creator      bool      `json:"creator"`
+    AddedAt      time.Time `json:"added_at"`
+}
+
+type AddMemberRequest struct {
+    User string `json:"user" binding:"required,max=255"` // email address or username
+    Role string `json:"role" binding:"required,oneof=viewer editor owner"`
+}
+
+type UpdateMemberRequest struct {
+    Role string `json:"role" binding:"required,oneof=viewer editor owner"`
+}
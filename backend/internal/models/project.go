@@ -7,31 +7,116 @@ import (
     "time"
 )
 
+// Project visibility levels. Private projects are only visible to their
+// owner, unlisted ones to anyone with the link, and public ones are also
+// listed on the homepage and author profiles.
+const (
+    VisibilityPrivate  = "private"
+    VisibilityUnlisted = "unlisted"
+    VisibilityPublic   = "public"
+)
+
+// Genres is the fixed list a project's genre is chosen from
+var Genres = []string{"fantasy", "romance", "sci-fi", "mystery", "thriller", "horror", "historical", "adventure", "drama", "comedy", "young-adult", "other"}
+
 type Project struct {
-    ID          int             `json:"id" db:"id"`
-    UserID      int             `json:"user_id" db:"user_id"`
-    Title       string          `json:"title" db:"title"`
-    Description *string         `json:"description" db:"description"`
-    CoverImage  *string         `json:"cover_image" db:"cover_image"`
-    ProjectData json.RawMessage `json:"project_data" db:"project_data"`
-    CreatedAt   time.Time       `json:"created_at" db:"created_at"`
-    UpdatedAt   time.Time       `json:"updated_at" db:"updated_at"`
+    ID           int               `json:"id" db:"id"`
+    UserID       int               `json:"user_id" db:"user_id"`
+    Title        string            `json:"title" db:"title"`
+    Description  *string           `json:"description" db:"description"`
+    CoverImage   *string           `json:"cover_image" db:"cover_image"`
+    ProjectData  json.RawMessage   `json:"project_data" db:"project_data"`
+    Visibility   string            `json:"visibility" db:"visibility"`
+    Genre        *string           `json:"genre" db:"genre"`
+    Tags         []string          `json:"tags" db:"-"`
+    Role         string            `json:"role,omitempty" db:"-"` // the caller's role, empty for public access
+    ForkedFromID *int              `json:"-" db:"forked_from"`
+    ForkedFrom   *ProjectReference `json:"forked_from,omitempty" db:"-"` // only set while the original is visible to everyone
+    StarCount    int               `json:"star_count" db:"-"`
+    CreatedAt    time.Time         `json:"created_at" db:"created_at"`
+    UpdatedAt    time.Time         `json:"updated_at" db:"updated_at"`
+}
+
+// ProjectReference identifies another project, such as the original of a fork
+type ProjectReference struct {
+    ID             int    `json:"id"`
+    Title          string `json:"title"`
+    AuthorName     string `json:"authorName"`
+    AuthorUsername string `json:"authorUsername"`
 }
 
 type ProjectListItem struct {
-    ID          int       `json:"id"`
-    Title       string    `json:"title"`
-    Description *string   `json:"description"`
-    CoverImage  *string   `json:"cover_image"`
-    CreatedAt   time.Time `json:"created_at"`
-    UpdatedAt   time.Time `json:"updated_at"`
-    AuthorName  string    `json:"authorName"`
+    ID             int       `json:"id"`
+    Title          string    `json:"title"`
+    Description    *string   `json:"description"`
+    CoverImage     *string   `json:"cover_image"`
+    Visibility     string    `json:"visibility"`
+    Genre          *string   `json:"genre"`
+    Tags           []string  `json:"tags"`
+    CreatedAt      time.Time `json:"created_at"`
+    UpdatedAt      time.Time `json:"updated_at"`
+    AuthorName     string    `json:"authorName"`
+    AuthorUsername string    `json:"authorUsername"`
+    StarCount      int       `json:"star_count"`
+    ForkCount      int       `json:"fork_count"`
+    EditorPick     bool      `json:"editor_pick"`
+    FolderID       *int      `json:"folder_id,omitempty"` // only in the caller's own listing
+}
+
+// TrashedProject is a deleted project that can still be restored until PurgeAt
+type TrashedProject struct {
+    ProjectListItem
+    DeletedAt time.Time `json:"deleted_at"`
+    PurgeAt   time.Time `json:"purge_at"`
+}
+
+// Project listing sort options
+const (
+    ProjectSortUpdated    = "updated"
+    ProjectSortCreated    = "created"
+    ProjectSortTitle      = "title"
+    ProjectSortPopularity = "popularity"
+    ProjectSortFeatured   = "featured"
+)
+
+// ProjectFilter narrows down, sorts and pages project listings. Folder only
+// applies to the caller's own listing. The date ranges are whole days and
+// include both ends.
+type ProjectFilter struct {
+    Folder      string    `form:"folder"` // a folder ID, or "none" for projects outside any folder
+    Tag         string    `form:"tag" binding:"max=30"`
+    Genre       string    `form:"genre" binding:"omitempty,oneof=fantasy romance sci-fi mystery thriller horror historical adventure drama comedy young-adult other"`
+    Sort        string    `form:"sort" binding:"omitempty,oneof=updated created title popularity featured"` // defaults to updated, or featured on /projects/featured
+    Order       string    `form:"order" binding:"omitempty,oneof=asc desc"` // defaults to asc for title, desc otherwise
+    Limit       int       `form:"limit" binding:"omitempty,min=1,max=100"`
+    Cursor      string    `form:"cursor" binding:"max=500"`
+    CreatedFrom time.Time `form:"created_from" time_format:"2006-01-02"`
+    CreatedTo   time.Time `form:"created_to" time_format:"2006-01-02"`
+    UpdatedFrom time.Time `form:"updated_from" time_format:"2006-01-02"`
+    UpdatedTo   time.Time `form:"updated_to" time_format:"2006-01-02"`
+}
+
+// StarStatus is the caller's star on a project and its total
+type StarStatus struct {
+    Starred   bool `json:"starred"`
+    StarCount int  `json:"star_count"`
+}
+
+// ProjectPage is one page of a project listing. NextCursor is set when
+// there are more projects; pass it back as ?cursor= to get them.
+type ProjectPage struct {
+    Projects   []ProjectListItem `json:"projects"`
+    NextCursor string            `json:"next_cursor,omitempty"`
+    HasMore    bool              `json:"has_more"`
 }
 
 type CreateProjectRequest struct {
     Title       string          `json:"title" binding:"required,max=255"`
     Description *string         `json:"description"`
     ProjectData json.RawMessage `json:"project_data"`
+    Visibility  string          `json:"visibility" binding:"omitempty,oneof=private unlisted public"` // defaults to private
+    TemplateID  string          `json:"template_id" binding:"max=50"` // seeds project_data when it is not given
+    Genre       string          `json:"genre" binding:"omitempty,oneof=fantasy romance sci-fi mystery thriller horror historical adventure drama comedy young-adult other"`
 }
 
 type UpdateProjectRequest struct {
@@ -39,6 +124,8 @@ type UpdateProjectRequest struct {
     Description *string           `json:"description"`
     CoverImage  *string           `json:"cover_image"`
     ProjectData *json.RawMessage  `json:"project_data,omitempty"` // ✅ เปลี่ยนเป็น pointer
+    Visibility  *string           `json:"visibility,omitempty" binding:"omitempty,oneof=private unlisted public"`
+    Genre       *string           `json:"genre,omitempty" binding:"omitempty,oneof=fantasy romance sci-fi mystery thriller horror historical adventure drama comedy young-adult other"` // empty string clears it
 }
 
 type ProjectData struct {
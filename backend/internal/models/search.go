@@ -0,0 +1,32 @@
+package models
+
+// SearchRequest is the query string of GET /search
+type SearchRequest struct {
+    Query string `form:"q" binding:"required,min=2,max=100"`
+    Limit int    `form:"limit" binding:"omitempty,min=1,max=50"`
+}
+
+// Search match fields
+const (
+    SearchFieldTitle            = "title"
+    SearchFieldDescription      = "description"
+    SearchFieldCharacter        = "character"
+    SearchFieldCharacterDetails = "character_details"
+    SearchFieldRelationship     = "relationship"
+)
+
+// SearchMatch is one place a query matched inside a project. Snippet is
+// HTML-escaped text with the matched words wrapped in <mark> tags.
+type SearchMatch struct {
+    Field     string `json:"field"`
+    ElementID string `json:"element_id,omitempty"`
+    Label     string `json:"label,omitempty"` // the character, or "Source → Target" for relationships
+    Snippet   string `json:"snippet"`
+}
+
+// SearchResult is a matching project, ranked by Score
+type SearchResult struct {
+    Project ProjectListItem `json:"project"`
+    Score   float64         `json:"score"`
+    Matches []SearchMatch   `json:"matches"`
+}
@@ -0,0 +1,20 @@
+package models
+
+import "time"
+
+// ClientInfo describes the device a request came from
+type ClientInfo struct {
+    IPAddress string
+    UserAgent string
+}
+
+// Session is an active login shown in the user's device list
+type Session struct {
+    ID         int       `json:"id"`
+    UserAgent  string    `json:"user_agent"`
+    IPAddress  string    `json:"ip_address"`
+    CreatedAt  time.Time `json:"created_at"`
+    LastSeenAt time.Time `json:"last_seen_at"`
+    ExpiresAt  time.Time `json:"expires_at"`
+    Current    bool      `json:"current"`
+}
@@ -0,0 +1,26 @@
+package models
+
+import "time"
+
+// ShareLink is a secret read-only link to a project. The token itself is
+// only returned once, when the link is created.
+type ShareLink struct {
+    ID           int        `json:"id"`
+    Label        string     `json:"label"`
+    HasPassword  bool       `json:"has_password"`
+    ExpiresAt    *time.Time `json:"expires_at"`
+    OpenCount    int        `json:"open_count"`
+    LastOpenedAt *time.Time `json:"last_opened_at"`
+    CreatedAt    time.Time  `json:"created_at"`
+}
+
+type CreateShareLinkRequest struct {
+    Label         string `json:"label" binding:"max=100"`
+    Password      string `json:"password" binding:"omitempty,min=4,max=72"`
+    ExpiresInDays *int   `json:"expires_in_days" binding:"omitempty,min=1,max=365"`
+}
+
+type CreateShareLinkResponse struct {
+    ShareLink
+    Token string `json:"token"`
+}
@@ -0,0 +1,15 @@
+package models
+
+// TagCount is one entry of the tag cloud
+type TagCount struct {
+    Tag   string `json:"tag"`
+    Count int    `json:"count"`
+}
+
+type SetTagsRequest struct {
+    Tags []string `json:"tags" binding:"max=10,dive,max=30"`
+}
+
+type TagCloudFilter struct {
+    Limit int `form:"limit" binding:"omitempty,min=1,max=200"`
+}
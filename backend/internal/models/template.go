@@ -0,0 +1,24 @@
+package models
+
+import (
+    "encoding/json"
+    "time"
+)
+
+// ProjectTemplate seeds the diagram of new projects. Built-in templates have
+// slug IDs, templates saved by users have numeric IDs.
+type ProjectTemplate struct {
+    ID                string          `json:"id"`
+    Name              string          `json:"name"`
+    Description       string          `json:"description"`
+    BuiltIn           bool            `json:"built_in"`
+    CharacterCount    int             `json:"character_count"`
+    RelationshipCount int             `json:"relationship_count"`
+    ProjectData       json.RawMessage `json:"project_data,omitempty"` // only included when fetching a single template
+    CreatedAt         *time.Time      `json:"created_at,omitempty"`
+}
+
+type SaveTemplateRequest struct {
+    Name        string `json:"name" binding:"required,max=100"`
+    Description string `json:"description" binding:"max=500"`
+}
@@ -0,0 +1,33 @@
+package models
+
+import "time"
+
+// Scopes a personal access token can be granted
+const (
+    ScopeProjectsRead  = "projects:read"
+    ScopeProjectsWrite = "projects:write"
+    ScopeProfile       = "profile"
+)
+
+// PersonalAccessToken is a long-lived token for scripts. The secret itself
+// is only returned once, when the token is created.
+type PersonalAccessToken struct {
+    ID         int        `json:"id"`
+    Name       string     `json:"name"`
+    Prefix     string     `json:"prefix"` // first characters of the token, to help users recognise it
+    Scopes     []string   `json:"scopes"`
+    ExpiresAt  *time.Time `json:"expires_at"`
+    LastUsedAt *time.Time `json:"last_used_at"`
+    CreatedAt  time.Time  `json:"created_at"`
+}
+
+type CreatePersonalAccessTokenRequest struct {
+    Name          string   `json:"name" binding:"required,max=100"`
+    Scopes        []string `json:"scopes" binding:"required,min=1,dive,oneof=projects:read projects:write profile"`
+    ExpiresInDays *int     `json:"expires_in_days" binding:"omitempty,min=1,max=3650"`
+}
+
+type CreatePersonalAccessTokenResponse struct {
+    PersonalAccessToken
+    Token string `json:"token"`
+}
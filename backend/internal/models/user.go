@@ -6,40 +6,143 @@ import (
 )
 
 type User struct {
-    ID           int       `json:"id" db:"id"`
-    UserName     string    `json:"user_name" db:"user_name"`
-    Email        string    `json:"email" db:"email"`
-    PasswordHash string    `json:"-" db:"password_hash"` // Don't expose password hash in JSON
-    ProfileImage *string   `json:"profile_image" db:"profile_image"`
-    CreatedAt    time.Time `json:"created_at" db:"created_at"`
-    UpdatedAt    time.Time `json:"updated_at" db:"updated_at"`
+    ID            int       `json:"id" db:"id"`
+    Username      string    `json:"username" db:"username"` // unique, URL-safe handle
+    UserName      string    `json:"user_name" db:"user_name"` // display name
+    Email         string    `json:"email" db:"email"`
+    Role          string    `json:"role" db:"role"`
+    PasswordHash  string    `json:"-" db:"password_hash"` // Don't expose password hash in JSON
+    ProfileImage  *string   `json:"profile_image" db:"profile_image"`
+    EmailVerified bool      `json:"email_verified" db:"email_verified_at"`
+    CreatedAt     time.Time `json:"created_at" db:"created_at"`
+    UpdatedAt     time.Time `json:"updated_at" db:"updated_at"`
 }
 
 type LoginRequest struct {
     Email    string `json:"email" binding:"required,email"`
-    Password string `json:"password" binding:"required,min=6"`
+    Password string `json:"password" binding:"required"`
 }
 
+// Password strength is checked against the configured password policy
 type RegisterRequest struct {
     UserName string `json:"user_name" binding:"required,min=2,max=50"`
+    Username string `json:"username" binding:"omitempty,min=2,max=30"` // generated from user_name when empty
     Email    string `json:"email" binding:"required,email"`
-    Password string `json:"password" binding:"required,min=6"`
+    Password string `json:"password" binding:"required"`
 }
 
 type LoginResponse struct {
-    Token   string `json:"token"`
-    UserID  int    `json:"user_id"`
-    Message string `json:"message"`
+    Token        string `json:"token"`
+    RefreshToken string `json:"refresh_token,omitempty"`
+    ExpiresIn    int    `json:"expires_in,omitempty"` // access token lifetime in seconds
+    UserID       int    `json:"user_id"`
+    Message      string `json:"message"`
+
+    // Set instead of the tokens above when the account uses two-factor authentication
+    TwoFactorRequired bool   `json:"two_factor_required,omitempty"`
+    ChallengeToken    string `json:"challenge_token,omitempty"`
+}
+
+type RefreshTokenRequest struct {
+    RefreshToken string `json:"refresh_token" binding:"required"`
+}
+
+type TokenResponse struct {
+    Token        string `json:"token"`
+    RefreshToken string `json:"refresh_token"`
+    ExpiresIn    int    `json:"expires_in"`
 }
 
 type UserProfile struct {
-    ID           int     `json:"id"`
-    UserName     string  `json:"user_name"`
-    Email        string  `json:"email"`
-    ProfileImage *string `json:"profile_image"`
+    ID            int     `json:"id"`
+    Username      string  `json:"username"`
+    UserName      string  `json:"user_name"`
+    Email         string  `json:"email"`
+    Role          string  `json:"role"`
+    Bio           *string `json:"bio"`
+    ProfileImage  *string `json:"profile_image"`
+    EmailVerified bool    `json:"email_verified"`
 }
 
 type UpdateProfileRequest struct {
     UserName     *string `json:"user_name,omitempty" binding:"omitempty,min=2,max=50"`
+    Username     *string `json:"username,omitempty" binding:"omitempty,min=2,max=30"`
+    Bio          *string `json:"bio,omitempty" binding:"omitempty,max=500"`
     ProfileImage *string `json:"profile_image,omitempty"`
-}
\ No newline at end of file
+}
+
+// PublicProfile is what anyone can see about an author
+type PublicProfile struct {
+    Username     string            `json:"username"`
+    UserName     string            `json:"user_name"`
+    Bio          *string           `json:"bio"`
+    ProfileImage *string           `json:"profile_image"`
+    CreatedAt    time.Time         `json:"created_at"`
+    Projects     []ProjectListItem `json:"projects"`
+}
+
+type ForgotPasswordRequest struct {
+    Email string `json:"email" binding:"required,email"`
+}
+
+type ResetPasswordRequest struct {
+    Token       string `json:"token" binding:"required"`
+    NewPassword string `json:"new_password" binding:"required"`
+}
+
+type ChangePasswordRequest struct {
+    CurrentPassword string `json:"current_password" binding:"required"`
+    NewPassword     string `json:"new_password" binding:"required"`
+}
+
+type VerifyEmailRequest struct {
+    Token string `json:"token" binding:"required"`
+}
+
+type ChangeEmailRequest struct {
+    NewEmail string `json:"new_email" binding:"required,email"`
+    Password string `json:"password" binding:"required"`
+}
+
+type TwoFactorLoginRequest struct {
+    ChallengeToken string `json:"challenge_token" binding:"required"`
+    Code           string `json:"code" binding:"required_without=RecoveryCode"`
+    RecoveryCode   string `json:"recovery_code"`
+}
+
+type TwoFactorSetupResponse struct {
+    Secret     string `json:"secret"`
+    OTPAuthURI string `json:"otpauth_uri"`
+}
+
+type TwoFactorCodeRequest struct {
+    Code string `json:"code" binding:"required"`
+}
+
+// DisableTwoFactorRequest needs the password unless the account has none,
+// e.g. one created through OIDC or after a forced password reset
+type DisableTwoFactorRequest struct {
+    Password     string `json:"password"`
+    Code         string `json:"code" binding:"required_without=RecoveryCode"`
+    RecoveryCode string `json:"recovery_code"`
+}
+
+type RecoveryCodesResponse struct {
+    RecoveryCodes []string `json:"recovery_codes"`
+}
+
+// Identity links a user to an account at an external OpenID Connect provider
+type Identity struct {
+    ID          int        `json:"id"`
+    Provider    string     `json:"provider"`
+    Email       *string    `json:"email"`
+    CreatedAt   time.Time  `json:"created_at"`
+    LastLoginAt *time.Time `json:"last_login_at"`
+}
+
+// OIDCCallbackResult is the outcome of a provider callback: either a login
+// or a newly linked identity
+type OIDCCallbackResult struct {
+    Login  *LoginResponse
+    Linked bool
+}
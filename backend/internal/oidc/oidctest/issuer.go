@@ -0,0 +1,186 @@
+// Package oidctest provides a local OpenID Connect issuer for tests. It
+// serves discovery, JWKS and token endpoints and enforces PKCE like a real
+// provider would.
+package oidctest
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"time"
+
+	"backend/pkg/jwk"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Identity is the user the issuer signs in. Audience and Nonce override the
+// values taken from the authorization request, to produce invalid tokens.
+type Identity struct {
+	Subject       string
+	Email         string
+	EmailVerified bool
+	Name          string
+	Audience      string
+	Nonce         string
+}
+
+// authorization is a code waiting to be redeemed at the token endpoint
+type authorization struct {
+	clientID      string
+	redirectURI   string
+	codeChallenge string
+	nonce         string
+	identity      Identity
+}
+
+// Issuer is a mock OpenID Connect provider backed by httptest.Server.
+type Issuer struct {
+	URL string
+
+	server *httptest.Server
+	key    ed25519.PrivateKey
+	jwk    jwk.Key
+
+	mu    sync.Mutex
+	codes map[string]authorization
+}
+
+// NewIssuer starts an issuer; call Close when done.
+func NewIssuer() *Issuer {
+	publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		panic(err)
+	}
+	key, err := jwk.NewKey(publicKey, "EdDSA")
+	if err != nil {
+		panic(err)
+	}
+
+	issuer := &Issuer{key: privateKey, jwk: key, codes: map[string]authorization{}}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", issuer.serveDiscovery)
+	mux.HandleFunc("/jwks", issuer.serveJWKS)
+	mux.HandleFunc("/token", issuer.serveToken)
+	issuer.server = httptest.NewServer(mux)
+	issuer.URL = issuer.server.URL
+	return issuer
+}
+
+func (i *Issuer) Close() {
+	i.server.Close()
+}
+
+// Authorize plays the user approving the login at authURL, as returned by
+// Provider.AuthCodeURL, and returns the code the provider would redirect
+// back with.
+func (i *Issuer) Authorize(authURL string, identity Identity) (string, error) {
+	parsed, err := url.Parse(authURL)
+	if err != nil {
+		return "", err
+	}
+	query := parsed.Query()
+	if query.Get("code_challenge_method") != "S256" || query.Get("code_challenge") == "" {
+		return "", errors.New("authorization request is missing a S256 code challenge")
+	}
+
+	code := randomString()
+	i.mu.Lock()
+	i.codes[code] = authorization{
+		clientID:      query.Get("client_id"),
+		redirectURI:   query.Get("redirect_uri"),
+		codeChallenge: query.Get("code_challenge"),
+		nonce:         query.Get("nonce"),
+		identity:      identity,
+	}
+	i.mu.Unlock()
+	return code, nil
+}
+
+func (i *Issuer) serveDiscovery(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]string{
+		"issuer":                 i.URL,
+		"authorization_endpoint": i.URL + "/authorize",
+		"token_endpoint":         i.URL + "/token",
+		"jwks_uri":               i.URL + "/jwks",
+	})
+}
+
+func (i *Issuer) serveJWKS(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, jwk.Set{Keys: []jwk.Key{i.jwk}})
+}
+
+func (i *Issuer) serveToken(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil || r.PostForm.Get("grant_type") != "authorization_code" {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_request"})
+		return
+	}
+
+	code := r.PostForm.Get("code")
+	i.mu.Lock()
+	auth, ok := i.codes[code]
+	delete(i.codes, code)
+	i.mu.Unlock()
+
+	sum := sha256.Sum256([]byte(r.PostForm.Get("code_verifier")))
+	switch {
+	case !ok,
+		auth.clientID != r.PostForm.Get("client_id"),
+		auth.redirectURI != r.PostForm.Get("redirect_uri"),
+		base64.RawURLEncoding.EncodeToString(sum[:]) != auth.codeChallenge:
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_grant"})
+		return
+	}
+
+	audience, nonce := auth.clientID, auth.nonce
+	if auth.identity.Audience != "" {
+		audience = auth.identity.Audience
+	}
+	if auth.identity.Nonce != "" {
+		nonce = auth.identity.Nonce
+	}
+
+	now := time.Now()
+	token := jwt.NewWithClaims(jwt.SigningMethodEdDSA, jwt.MapClaims{
+		"iss":            i.URL,
+		"sub":            auth.identity.Subject,
+		"aud":            audience,
+		"iat":            now.Unix(),
+		"exp":            now.Add(5 * time.Minute).Unix(),
+		"nonce":          nonce,
+		"email":          auth.identity.Email,
+		"email_verified": auth.identity.EmailVerified,
+		"name":           auth.identity.Name,
+	})
+	token.Header["kid"] = i.jwk.Kid
+	idToken, err := token.SignedString(i.key)
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "server_error"})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]string{
+		"access_token": randomString(),
+		"token_type":   "Bearer",
+		"id_token":     idToken,
+	})
+}
+
+func writeJSON(w http.ResponseWriter, status int, value interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(value)
+}
+
+func randomString() string {
+	bytes := make([]byte, 16)
+	rand.Read(bytes)
+	return base64.RawURLEncoding.EncodeToString(bytes)
+}
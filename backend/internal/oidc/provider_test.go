@@ -0,0 +1,111 @@
+package oidc
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"backend/internal/config"
+	"backend/internal/oidc/oidctest"
+)
+
+const testRedirectURL = "http://localhost:8080/api/auth/oidc/mock/callback"
+
+func newTestProvider(issuer *oidctest.Issuer) *Provider {
+	return NewProvider(config.OIDCProviderConfig{
+		Name:     "mock",
+		Issuer:   issuer.URL,
+		ClientID: "novelsync",
+		Scopes:   []string{"openid", "email"},
+	}, testRedirectURL, nil)
+}
+
+// login runs the authorization code flow up to the callback and returns the
+// code with the verifier and nonce the client stored.
+func login(t *testing.T, issuer *oidctest.Issuer, provider *Provider, identity oidctest.Identity) (string, string, string) {
+	t.Helper()
+	nonce, _ := GenerateState()
+	verifier, _ := GenerateCodeVerifier()
+	authURL, err := provider.AuthCodeURL(context.Background(), "state", nonce, verifier)
+	if err != nil {
+		t.Fatalf("AuthCodeURL: %v", err)
+	}
+	code, err := issuer.Authorize(authURL, identity)
+	if err != nil {
+		t.Fatalf("Authorize: %v", err)
+	}
+	return code, verifier, nonce
+}
+
+func TestExchangeReturnsVerifiedClaims(t *testing.T) {
+	issuer := oidctest.NewIssuer()
+	defer issuer.Close()
+	provider := newTestProvider(issuer)
+
+	code, verifier, nonce := login(t, issuer, provider, oidctest.Identity{Subject: "alice", Email: "alice@example.com", EmailVerified: true})
+	claims, err := provider.Exchange(context.Background(), code, verifier, nonce)
+	if err != nil {
+		t.Fatalf("Exchange: %v", err)
+	}
+	if claims.Subject != "alice" || claims.Email != "alice@example.com" || !claims.EmailVerified {
+		t.Errorf("unexpected claims: %+v", claims)
+	}
+}
+
+func TestExchangeRejectsWrongCodeVerifier(t *testing.T) {
+	issuer := oidctest.NewIssuer()
+	defer issuer.Close()
+	provider := newTestProvider(issuer)
+
+	code, _, nonce := login(t, issuer, provider, oidctest.Identity{Subject: "alice"})
+	other, _ := GenerateCodeVerifier()
+	if _, err := provider.Exchange(context.Background(), code, other, nonce); err == nil || !strings.Contains(err.Error(), "invalid_grant") {
+		t.Fatalf("expected invalid_grant for a wrong PKCE verifier, got %v", err)
+	}
+}
+
+func TestExchangeRejectsReusedCode(t *testing.T) {
+	issuer := oidctest.NewIssuer()
+	defer issuer.Close()
+	provider := newTestProvider(issuer)
+
+	code, verifier, nonce := login(t, issuer, provider, oidctest.Identity{Subject: "alice"})
+	if _, err := provider.Exchange(context.Background(), code, verifier, nonce); err != nil {
+		t.Fatalf("Exchange: %v", err)
+	}
+	if _, err := provider.Exchange(context.Background(), code, verifier, nonce); err == nil {
+		t.Fatal("expected a redeemed code to be rejected")
+	}
+}
+
+func TestExchangeRejectsNonceMismatch(t *testing.T) {
+	issuer := oidctest.NewIssuer()
+	defer issuer.Close()
+	provider := newTestProvider(issuer)
+
+	code, verifier, nonce := login(t, issuer, provider, oidctest.Identity{Subject: "alice", Nonce: "replayed"})
+	if _, err := provider.Exchange(context.Background(), code, verifier, nonce); err == nil || !strings.Contains(err.Error(), "nonce") {
+		t.Fatalf("expected a nonce mismatch, got %v", err)
+	}
+}
+
+func TestExchangeRejectsOtherAudience(t *testing.T) {
+	issuer := oidctest.NewIssuer()
+	defer issuer.Close()
+	provider := newTestProvider(issuer)
+
+	code, verifier, nonce := login(t, issuer, provider, oidctest.Identity{Subject: "alice", Audience: "another-client"})
+	if _, err := provider.Exchange(context.Background(), code, verifier, nonce); err == nil || !strings.Contains(err.Error(), "aud") {
+		t.Fatalf("expected the audience to be rejected, got %v", err)
+	}
+}
+
+func TestDiscoveryRejectsIssuerMismatch(t *testing.T) {
+	issuer := oidctest.NewIssuer()
+	defer issuer.Close()
+	provider := NewProvider(config.OIDCProviderConfig{Name: "mock", Issuer: issuer.URL + "/other", ClientID: "novelsync"}, testRedirectURL, nil)
+
+	if _, err := provider.AuthCodeURL(context.Background(), "state", "nonce", "verifier"); err == nil {
+		t.Fatal("expected discovery to fail for a different issuer")
+	}
+}
@@ -6,21 +6,61 @@ import (
     "net/http"
     "backend/internal/config"
     "backend/internal/handlers"
+    "backend/internal/mailer"
     "backend/internal/middleware"
     "backend/internal/models"
     "backend/internal/services"
+    "backend/internal/signing"
 
     "github.com/gin-gonic/gin"
 )
 
-func SetupRoutes(router *gin.Engine, db *sql.DB, cfg *config.Config) {
+func SetupRoutes(router *gin.Engine, db *sql.DB, cfg *config.Config, mail mailer.Mailer, keys *signing.KeySet) {
     // Initialize services
-    authService := services.NewAuthService(db, cfg)
-    projectService := services.NewProjectService(db)
+    sessionService := services.NewSessionService(db)
+    emailService := services.NewEmailService(db, cfg, mail)
+    passwordPolicy := services.NewPasswordPolicy(cfg.Auth)
+    twoFactorService := services.NewTwoFactorService(db, cfg)
+    throttleService := services.NewLoginThrottleService(db, cfg)
+    authService := services.NewAuthService(db, cfg, sessionService, emailService, passwordPolicy, twoFactorService, throttleService, keys)
+    passwordService := services.NewPasswordService(db, cfg, mail, sessionService, passwordPolicy)
+    oidcService := services.NewOIDCService(db, cfg, authService, nil)
+    projectService := services.NewProjectService(db, cfg)
+    memberService := services.NewProjectMemberService(db, projectService)
+    shareService := services.NewShareLinkService(db, projectService)
+    templateService := services.NewTemplateService(db, projectService)
+    folderService := services.NewFolderService(db, projectService)
+    tagService := services.NewTagService(db, projectService)
+    starService := services.NewStarService(db, projectService)
+    commentService := services.NewCommentService(db, projectService)
+    searchService := services.NewSearchService(db, projectService, services.NewCharacterService(db))
+    tokenService := services.NewPersonalTokenService(db)
+    userService := services.NewUserService(db, projectService)
+    adminService := services.NewAdminService(db, sessionService, passwordService)
 
     // Initialize handlers
     authHandler := handlers.NewAuthHandler(authService)
+    passwordHandler := handlers.NewPasswordHandler(passwordService)
+    emailHandler := handlers.NewEmailHandler(emailService)
+    twoFactorHandler := handlers.NewTwoFactorHandler(authService, twoFactorService)
+    oidcHandler := handlers.NewOIDCHandler(oidcService, cfg.Server.FrontendURL)
     projectHandler := handlers.NewProjectHandler(projectService)
+    memberHandler := handlers.NewProjectMemberHandler(memberService)
+    shareHandler := handlers.NewShareLinkHandler(shareService)
+    templateHandler := handlers.NewTemplateHandler(templateService)
+    folderHandler := handlers.NewFolderHandler(folderService)
+    tagHandler := handlers.NewTagHandler(tagService)
+    starHandler := handlers.NewStarHandler(starService)
+    commentHandler := handlers.NewCommentHandler(commentService)
+    searchHandler := handlers.NewSearchHandler(searchService)
+    tokenHandler := handlers.NewPersonalTokenHandler(tokenService)
+    sessionHandler := handlers.NewSessionHandler(sessionService)
+    userHandler := handlers.NewUserHandler(userService)
+    adminHandler := handlers.NewAdminHandler(adminService)
+    jwksHandler := handlers.NewJWKSHandler(keys)
+
+    // Public keys for verifying access tokens
+    router.GET("/.well-known/jwks.json", jwksHandler.GetJWKS)
 
     // API v1 routes
     v1 := router.Group("/api")
@@ -28,15 +68,39 @@ func SetupRoutes(router *gin.Engine, db *sql.DB, cfg *config.Config) {
         // Public routes (no authentication required)
         public := v1.Group("")
         {
-            // Authentication routes
-            public.POST("/register", authHandler.Register)
-            public.POST("/login", authHandler.Login)
+            // Authentication routes (rate limited per client IP)
+            authRoutes := public.Group("")
+            authRoutes.Use(middleware.RateLimitMiddleware(cfg.Auth.RateLimitRequests, cfg.Auth.RateLimitWindow))
+            {
+                authRoutes.POST("/register", authHandler.Register)
+                authRoutes.POST("/login", authHandler.Login)
+                authRoutes.POST("/login/2fa", twoFactorHandler.Login)
+                authRoutes.POST("/token/refresh", authHandler.RefreshToken)
+                authRoutes.POST("/password/forgot", passwordHandler.ForgotPassword)
+                authRoutes.POST("/password/reset", passwordHandler.ResetPassword)
+                authRoutes.POST("/email/verify", emailHandler.VerifyEmail)
+
+                // Secret project links, limited too since they can carry a password
+                authRoutes.GET("/shared/:token", shareHandler.GetSharedProject)
+            }
+
+            // OpenID Connect login
+            public.GET("/auth/oidc/providers", oidcHandler.GetProviders)
+            public.GET("/auth/oidc/:provider", oidcHandler.StartLogin)
+            public.GET("/auth/oidc/:provider/callback", oidcHandler.Callback)
             
             // Public project routes (for homepage)
             public.GET("/projects", projectHandler.GetAllProjects)
             
+            // Discovery: genres and the tag cloud of public projects
+            public.GET("/genres", tagHandler.GetGenres)
+            public.GET("/tags", tagHandler.GetTagCloud)
+            
             // ✅ เพิ่ม public project view route
             public.GET("/projects/public/:id", projectHandler.GetPublicProject)
+
+            // Public author profiles
+            public.GET("/users/:username", userHandler.GetPublicProfile)
             
             // Health check
             public.GET("/ping", func(c *gin.Context) {
@@ -47,53 +111,143 @@ func SetupRoutes(router *gin.Engine, db *sql.DB, cfg *config.Config) {
             })
         }
 
-        // Protected routes (authentication required)
+        // Protected routes (authentication required). Personal access tokens
+        // are accepted too, limited by the scopes each group requires.
         protected := v1.Group("")
-        protected.Use(middleware.AuthMiddleware(authService))
+        protected.Use(middleware.AuthMiddleware(authService, tokenService))
         {
-            // User/Profile routes
+            // Profile routes
+            profile := protected.Group("/user")
+            profile.Use(middleware.RequireScope(models.ScopeProfile))
+            {
+                profile.GET("/profile", authHandler.GetProfile)
+                profile.PUT("/profile", authHandler.UpdateProfile)
+            }
+
+            // Account routes (login sessions only)
             user := protected.Group("/user")
+            user.Use(middleware.RequireSession())
             {
-                user.GET("/profile", authHandler.GetProfile)
-                user.PUT("/profile", authHandler.UpdateProfile)
                 user.POST("/logout", authHandler.Logout)
+
+                // Active sessions
+                user.GET("/sessions", sessionHandler.GetSessions)
+                user.DELETE("/sessions", sessionHandler.DeleteOtherSessions)
+                user.DELETE("/sessions/:id", sessionHandler.DeleteSession)
                 user.GET("/validate", authHandler.ValidateToken)
+                user.PUT("/email", emailHandler.ChangeEmail)
+                user.PUT("/password", passwordHandler.ChangePassword)
+
+                // Two-factor authentication
+                user.POST("/2fa/setup", twoFactorHandler.Setup)
+                user.POST("/2fa/confirm", twoFactorHandler.Confirm)
+                user.POST("/2fa/disable", twoFactorHandler.Disable)
+                user.POST("/2fa/recovery-codes", twoFactorHandler.RegenerateRecoveryCodes)
+
+                // Linked identity providers
+                user.GET("/identities", oidcHandler.GetIdentities)
+                user.POST("/identities/:provider", oidcHandler.StartLink)
+                user.DELETE("/identities/:id", oidcHandler.DeleteIdentity)
+
+                // Personal access tokens
+                user.GET("/tokens", tokenHandler.GetTokens)
+                user.POST("/tokens", tokenHandler.CreateToken)
+                user.DELETE("/tokens/:id", tokenHandler.RevokeToken)
+            }
+
+            // Email verification routes
+            protected.POST("/email/resend", middleware.RequireSession(), emailHandler.ResendVerification)
+
+            // Moderation routes. Moderators manage regular users and
+            // projects; account-level actions are reserved for admins.
+            admin := protected.Group("/admin")
+            admin.Use(middleware.RequireSession(), middleware.RequireRole(models.RoleModerator, models.RoleAdmin))
+            {
+                admin.GET("/users", adminHandler.GetUsers)
+                admin.POST("/users/:id/suspend", adminHandler.SuspendUser)
+                admin.POST("/users/:id/unsuspend", adminHandler.UnsuspendUser)
+                admin.POST("/users/:id/password-reset", middleware.RequireRole(models.RoleAdmin), adminHandler.ForcePasswordReset)
+                admin.PUT("/users/:id/role", middleware.RequireRole(models.RoleAdmin), adminHandler.UpdateRole)
+                admin.DELETE("/users/:id", middleware.RequireRole(models.RoleAdmin), adminHandler.DeleteUser)
+
+                admin.GET("/projects", adminHandler.GetProjects)
+                admin.POST("/projects/:id/unpublish", adminHandler.UnpublishProject)
+                admin.POST("/projects/:id/republish", adminHandler.RepublishProject)
+                admin.POST("/projects/:id/pick", middleware.RequireRole(models.RoleAdmin), adminHandler.PickProject)
+                admin.DELETE("/projects/:id/pick", middleware.RequireRole(models.RoleAdmin), adminHandler.UnpickProject)
             }
 
             // Project routes
+            protected.GET("/projects/my", middleware.RequireScope(models.ScopeProjectsRead), projectHandler.GetProjects) // Get user's projects
+            protected.GET("/projects/trash", middleware.RequireScope(models.ScopeProjectsRead), projectHandler.GetTrash)
+            protected.GET("/projects/starred", middleware.RequireScope(models.ScopeProjectsRead), starHandler.GetStarredProjects)
+            protected.GET("/projects/:id/members", middleware.RequireScope(models.ScopeProjectsRead), memberHandler.GetMembers)
+            protected.GET("/projects/:id/share-links", middleware.RequireScope(models.ScopeProjectsRead), shareHandler.GetShareLinks)
+            protected.DELETE("/templates/:id", middleware.RequireScope(models.ScopeProjectsWrite), templateHandler.DeleteTemplate)
+            protected.GET("/folders", middleware.RequireScope(models.ScopeProjectsRead), folderHandler.GetFolders)
+
+            folders := protected.Group("/folders")
+            folders.Use(middleware.RequireScope(models.ScopeProjectsWrite))
+            {
+                folders.POST("", folderHandler.CreateFolder)
+                folders.PUT("/:id", folderHandler.UpdateFolder)
+                folders.DELETE("/:id", folderHandler.DeleteFolder)
+            }
+
             projects := protected.Group("/projects")
+            projects.Use(middleware.RequireScope(models.ScopeProjectsWrite))
             {
                 // CRUD operations
                 projects.POST("", projectHandler.CreateProject)
-                projects.GET("/my", projectHandler.GetProjects) // Get user's projects
                 projects.PUT("/:id", projectHandler.UpdateProject)
-                projects.DELETE("/:id", projectHandler.DeleteProject)
+                projects.DELETE("/:id", projectHandler.DeleteProject) // moves it to the trash
+                projects.POST("/:id/restore", projectHandler.RestoreProject)
+                projects.DELETE("/:id/permanent", projectHandler.PurgeProject)
                 
                 // Special operations
                 projects.POST("/:id/save", projectHandler.SaveProjectData)
                 projects.POST("/:id/autosave", projectHandler.AutoSave)
+                projects.POST("/:id/fork", projectHandler.ForkProject)
+                projects.POST("/:id/template", templateHandler.SaveTemplate)
+                projects.PUT("/:id/folder", folderHandler.MoveProject)
+                projects.PUT("/:id/tags", tagHandler.SetTags)
+                projects.DELETE("/:id/tags/:tag", tagHandler.RemoveTag)
+                projects.PUT("/:id/star", starHandler.StarProject)
+                projects.DELETE("/:id/star", starHandler.UnstarProject)
+
+                // Collaborators
+                projects.POST("/:id/members", memberHandler.AddMember)
+                projects.PUT("/:id/members/:userId", memberHandler.UpdateMember)
+                projects.DELETE("/:id/members/:userId", memberHandler.RemoveMember)
+
+                // Share links
+                projects.POST("/:id/share-links", shareHandler.CreateShareLink)
+                projects.DELETE("/:id/share-links/:linkId", shareHandler.RevokeShareLink)
+
+                // Comments
+                projects.POST("/:id/comments", commentHandler.CreateComment)
+                projects.PUT("/:id/comments/:commentId", commentHandler.UpdateComment)
+                projects.DELETE("/:id/comments/:commentId", commentHandler.DeleteComment)
             }
         }
 
         // ✅ Optional authenticated routes (work with or without auth)
         optional := v1.Group("")
-        optional.Use(middleware.OptionalAuthMiddleware(authService))
+        optional.Use(middleware.OptionalAuthMiddleware(authService, tokenService), middleware.RequireScope(models.ScopeProjectsRead))
         {
             // ✅ Project access route - ใช้ได้ทั้งแบบ login และไม่ login
             optional.GET("/projects/:id", projectHandler.GetProject)
+            optional.GET("/projects/:id/comments", commentHandler.GetComments)
+
+            // Templates: built-ins for everyone, plus the user's own
+            optional.GET("/templates", templateHandler.GetTemplates)
+            optional.GET("/templates/:id", templateHandler.GetTemplate)
+
+            // Search across projects and the characters inside them
+            optional.GET("/search", searchHandler.Search)
             
-            // Routes that provide different responses based on authentication
-            optional.GET("/projects/featured", func(c *gin.Context) {
-                projects, err := projectService.GetAllProjects()
-                if err != nil {
-                    c.JSON(http.StatusInternalServerError, models.ErrorResponse{
-                        Error:   "fetch_failed",
-                        Message: err.Error(),
-                    })
-                    return
-                }
-                c.JSON(http.StatusOK, projects)
-            })
+            // Editor picks first, then public projects by time-decayed popularity
+            optional.GET("/projects/featured", projectHandler.GetFeaturedProjects)
         }
     }
 
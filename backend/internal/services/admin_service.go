@@ -0,0 +1,402 @@
+package services
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+
+	"backend/internal/models"
+)
+
+const defaultAdminPageSize = 50
+
+var (
+	// ErrCannotModerateSelf is returned when a moderator targets their own account.
+	ErrCannotModerateSelf = errors.New("you cannot perform this action on your own account")
+	// ErrInsufficientRole is returned when the target's role is not below the actor's.
+	ErrInsufficientRole = errors.New("you can only manage users with a lower role than yours")
+)
+
+// AdminService implements the moderation tools behind /api/admin.
+type AdminService struct {
+	db        *sql.DB
+	sessions  *SessionService
+	passwords *PasswordService
+}
+
+func NewAdminService(db *sql.DB, sessionService *SessionService, passwordService *PasswordService) *AdminService {
+	return &AdminService{
+		db:        db,
+		sessions:  sessionService,
+		passwords: passwordService,
+	}
+}
+
+// ListUsers searches accounts by username, display name or email.
+func (s *AdminService) ListUsers(filter models.AdminUserFilter) (*models.AdminUserList, error) {
+	where := []string{"1 = 1"}
+	args := []interface{}{}
+
+	if filter.Query != "" {
+		pattern := "%" + filter.Query + "%"
+		where = append(where, "(u.username LIKE ? OR u.user_name LIKE ? OR u.email LIKE ?)")
+		args = append(args, pattern, pattern, pattern)
+	}
+	if filter.Role != "" {
+		where = append(where, "u.role = ?")
+		args = append(args, filter.Role)
+	}
+	if filter.Suspended != nil {
+		if *filter.Suspended {
+			where = append(where, "u.suspended_at IS NOT NULL")
+		} else {
+			where = append(where, "u.suspended_at IS NULL")
+		}
+	}
+	condition := strings.Join(where, " AND ")
+
+	list := &models.AdminUserList{Users: []models.AdminUser{}}
+	if err := s.db.QueryRow("SELECT COUNT(*) FROM users u WHERE "+condition, args...).Scan(&list.Total); err != nil {
+		return nil, fmt.Errorf("error counting users: %w", err)
+	}
+
+	limit, offset := pageBounds(filter.Limit, filter.Offset)
+	rows, err := s.db.Query(`
+        SELECT u.id, u.username, u.user_name, u.email, u.role, u.email_verified_at IS NOT NULL,
+               u.suspended_at, u.suspension_reason,
+               (SELECT COUNT(*) FROM projects p WHERE p.user_id = u.id), u.created_at
+        FROM users u
+        WHERE `+condition+`
+        ORDER BY u.created_at DESC
+        LIMIT ? OFFSET ?
+    `, append(args, limit, offset)...)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching users: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var user models.AdminUser
+		err := rows.Scan(
+			&user.ID,
+			&user.Username,
+			&user.UserName,
+			&user.Email,
+			&user.Role,
+			&user.EmailVerified,
+			&user.SuspendedAt,
+			&user.SuspensionReason,
+			&user.ProjectCount,
+			&user.CreatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning user: %w", err)
+		}
+		list.Users = append(list.Users, user)
+	}
+
+	return list, nil
+}
+
+// SuspendUser blocks the account and signs it out everywhere.
+func (s *AdminService) SuspendUser(actorID int, actorRole string, userID int, reason string) error {
+	if err := s.checkCanManage(actorID, actorRole, userID); err != nil {
+		return err
+	}
+
+	_, err := s.db.Exec(`
+        UPDATE users SET suspended_at = NOW(), suspension_reason = ? WHERE id = ?
+    `, nullableString(reason), userID)
+	if err != nil {
+		return fmt.Errorf("error suspending user: %w", err)
+	}
+	if err := s.sessions.RevokeAllSessions(userID); err != nil {
+		return err
+	}
+
+	log.Printf("[ADMIN] user %d suspended user %d: %s", actorID, userID, reason)
+	return nil
+}
+
+// UnsuspendUser lifts a suspension. The user has to log in again.
+func (s *AdminService) UnsuspendUser(actorID int, actorRole string, userID int) error {
+	if err := s.checkCanManage(actorID, actorRole, userID); err != nil {
+		return err
+	}
+
+	_, err := s.db.Exec("UPDATE users SET suspended_at = NULL, suspension_reason = NULL WHERE id = ?", userID)
+	if err != nil {
+		return fmt.Errorf("error unsuspending user: %w", err)
+	}
+
+	log.Printf("[ADMIN] user %d unsuspended user %d", actorID, userID)
+	return nil
+}
+
+// ForcePasswordReset clears the user's password, signs them out and emails
+// them a reset link. Logins with linked identity providers keep working.
+func (s *AdminService) ForcePasswordReset(actorID int, actorRole string, userID int) error {
+	if err := s.checkCanManage(actorID, actorRole, userID); err != nil {
+		return err
+	}
+
+	var email string
+	if err := s.db.QueryRow("SELECT email FROM users WHERE id = ?", userID).Scan(&email); err != nil {
+		return fmt.Errorf("error finding user: %w", err)
+	}
+
+	if _, err := s.db.Exec("UPDATE users SET password_hash = '' WHERE id = ?", userID); err != nil {
+		return fmt.Errorf("error clearing password: %w", err)
+	}
+	if err := s.sessions.RevokeAllSessions(userID); err != nil {
+		return err
+	}
+	if err := s.passwords.RequestPasswordReset(models.ForgotPasswordRequest{Email: email}); err != nil {
+		return err
+	}
+
+	log.Printf("[ADMIN] user %d forced a password reset for user %d", actorID, userID)
+	return nil
+}
+
+// DeleteUser permanently deletes the account and everything it owns.
+func (s *AdminService) DeleteUser(actorID int, actorRole string, userID int) error {
+	if err := s.checkCanManage(actorID, actorRole, userID); err != nil {
+		return err
+	}
+
+	if _, err := s.db.Exec("DELETE FROM users WHERE id = ?", userID); err != nil {
+		return fmt.Errorf("error deleting user: %w", err)
+	}
+
+	log.Printf("[ADMIN] user %d deleted user %d", actorID, userID)
+	return nil
+}
+
+// SetRole changes a user's role. Admins cannot change their own role, so
+// there is always at least one admin left.
+func (s *AdminService) SetRole(actorID int, userID int, role string) error {
+	if actorID == userID {
+		return ErrCannotModerateSelf
+	}
+
+	result, err := s.db.Exec("UPDATE users SET role = ? WHERE id = ?", role, userID)
+	if err != nil {
+		return fmt.Errorf("error updating role: %w", err)
+	}
+	if affected, err := result.RowsAffected(); err == nil && affected == 0 {
+		if _, err := s.userRole(userID); err != nil {
+			return err
+		}
+	}
+
+	log.Printf("[ADMIN] user %d set the role of user %d to %s", actorID, userID, role)
+	return nil
+}
+
+// ListProjects searches all projects, including unpublished ones.
+func (s *AdminService) ListProjects(filter models.AdminProjectFilter) (*models.AdminProjectList, error) {
+	where := []string{"1 = 1"}
+	args := []interface{}{}
+
+	if filter.Query != "" {
+		pattern := "%" + filter.Query + "%"
+		where = append(where, "(p.title LIKE ? OR u.username LIKE ? OR u.user_name LIKE ?)")
+		args = append(args, pattern, pattern, pattern)
+	}
+	if filter.UserID != 0 {
+		where = append(where, "p.user_id = ?")
+		args = append(args, filter.UserID)
+	}
+	if filter.Unpublished != nil {
+		if *filter.Unpublished {
+			where = append(where, "p.unpublished_at IS NOT NULL")
+		} else {
+			where = append(where, "p.unpublished_at IS NULL")
+		}
+	}
+	condition := strings.Join(where, " AND ")
+
+	list := &models.AdminProjectList{Projects: []models.AdminProject{}}
+	err := s.db.QueryRow(`
+        SELECT COUNT(*) FROM projects p JOIN users u ON p.user_id = u.id WHERE `+condition, args...).Scan(&list.Total)
+	if err != nil {
+		return nil, fmt.Errorf("error counting projects: %w", err)
+	}
+
+	limit, offset := pageBounds(filter.Limit, filter.Offset)
+	rows, err := s.db.Query(`
+        SELECT p.id, p.title, p.description, p.cover_image, p.visibility, p.created_at, p.updated_at,
+               u.user_name, u.username, p.user_id, p.unpublished_at, p.unpublish_reason, p.picked_at
+        FROM projects p
+        JOIN users u ON p.user_id = u.id
+        WHERE `+condition+`
+        ORDER BY p.updated_at DESC
+        LIMIT ? OFFSET ?
+    `, append(args, limit, offset)...)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching projects: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var project models.AdminProject
+		err := rows.Scan(
+			&project.ID,
+			&project.Title,
+			&project.Description,
+			&project.CoverImage,
+			&project.Visibility,
+			&project.CreatedAt,
+			&project.UpdatedAt,
+			&project.AuthorName,
+			&project.AuthorUsername,
+			&project.UserID,
+			&project.UnpublishedAt,
+			&project.UnpublishReason,
+			&project.PickedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning project: %w", err)
+		}
+		project.EditorPick = project.PickedAt != nil
+		list.Projects = append(list.Projects, project)
+	}
+
+	return list, nil
+}
+
+// UnpublishProject hides a project from everyone but its owner. Moderation
+// is not an edit, so updated_at is left alone.
+func (s *AdminService) UnpublishProject(actorID, projectID int, reason string) error {
+	result, err := s.db.Exec(`
+        UPDATE projects SET unpublished_at = NOW(), unpublish_reason = ?, updated_at = updated_at WHERE id = ?
+    `, nullableString(reason), projectID)
+	if err != nil {
+		return fmt.Errorf("error unpublishing project: %w", err)
+	}
+	if affected, err := result.RowsAffected(); err == nil && affected == 0 {
+		return ErrProjectNotFound
+	}
+
+	log.Printf("[ADMIN] user %d unpublished project %d: %s", actorID, projectID, reason)
+	return nil
+}
+
+// RepublishProject reverses UnpublishProject.
+func (s *AdminService) RepublishProject(actorID, projectID int) error {
+	result, err := s.db.Exec(`
+        UPDATE projects SET unpublished_at = NULL, unpublish_reason = NULL, updated_at = updated_at WHERE id = ?
+    `, projectID)
+	if err != nil {
+		return fmt.Errorf("error republishing project: %w", err)
+	}
+	if affected, err := result.RowsAffected(); err == nil && affected == 0 {
+		var id int
+		if err := s.db.QueryRow("SELECT id FROM projects WHERE id = ?", projectID).Scan(&id); err == sql.ErrNoRows {
+			return ErrProjectNotFound
+		}
+	}
+
+	log.Printf("[ADMIN] user %d republished project %d", actorID, projectID)
+	return nil
+}
+
+// PickProject makes a project an editor pick, shown first on the featured
+// list while it is public.
+func (s *AdminService) PickProject(actorID, projectID int) error {
+	result, err := s.db.Exec(`
+        UPDATE projects SET picked_at = COALESCE(picked_at, NOW()), updated_at = updated_at WHERE id = ?
+    `, projectID)
+	if err != nil {
+		return fmt.Errorf("error picking project: %w", err)
+	}
+	if affected, err := result.RowsAffected(); err == nil && affected == 0 {
+		if err := s.checkProjectExists(projectID); err != nil {
+			return err
+		}
+	}
+
+	log.Printf("[ADMIN] user %d picked project %d", actorID, projectID)
+	return nil
+}
+
+// UnpickProject reverses PickProject.
+func (s *AdminService) UnpickProject(actorID, projectID int) error {
+	result, err := s.db.Exec("UPDATE projects SET picked_at = NULL, updated_at = updated_at WHERE id = ?", projectID)
+	if err != nil {
+		return fmt.Errorf("error unpicking project: %w", err)
+	}
+	if affected, err := result.RowsAffected(); err == nil && affected == 0 {
+		if err := s.checkProjectExists(projectID); err != nil {
+			return err
+		}
+	}
+
+	log.Printf("[ADMIN] user %d unpicked project %d", actorID, projectID)
+	return nil
+}
+
+func (s *AdminService) checkProjectExists(projectID int) error {
+	var id int
+	err := s.db.QueryRow("SELECT id FROM projects WHERE id = ?", projectID).Scan(&id)
+	if err == sql.ErrNoRows {
+		return ErrProjectNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("error finding project: %w", err)
+	}
+	return nil
+}
+
+// checkCanManage allows acting only on other users with a lower role.
+func (s *AdminService) checkCanManage(actorID int, actorRole string, userID int) error {
+	if actorID == userID {
+		return ErrCannotModerateSelf
+	}
+
+	targetRole, err := s.userRole(userID)
+	if err != nil {
+		return err
+	}
+	if RoleRank(targetRole) >= RoleRank(actorRole) {
+		return ErrInsufficientRole
+	}
+	return nil
+}
+
+func (s *AdminService) userRole(userID int) (string, error) {
+	var role string
+	err := s.db.QueryRow("SELECT role FROM users WHERE id = ?", userID).Scan(&role)
+	if err == sql.ErrNoRows {
+		return "", ErrUserNotFound
+	}
+	if err != nil {
+		return "", fmt.Errorf("error finding user: %w", err)
+	}
+	return role, nil
+}
+
+// RoleRank orders roles by privilege; unknown roles rank lowest.
+func RoleRank(role string) int {
+	switch role {
+	case models.RoleAdmin:
+		return 2
+	case models.RoleModerator:
+		return 1
+	default:
+		return 0
+	}
+}
+
+func pageBounds(limit, offset int) (int, int) {
+	if limit <= 0 {
+		limit = defaultAdminPageSize
+	}
+	if offset < 0 {
+		offset = 0
+	}
+	return limit, offset
+}
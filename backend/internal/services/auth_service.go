@@ -5,8 +5,11 @@ import (
     "database/sql"
     "errors"
     "fmt"
+    "log"
     "backend/internal/config"
     "backend/internal/models"
+    "backend/internal/signing"
+    "backend/pkg/utils"
     "time"
 
     "github.com/golang-jwt/jwt/v5"
@@ -14,63 +17,148 @@ import (
 )
 
 type AuthService struct {
-    db     *sql.DB
-    config *config.Config
+    db       *sql.DB
+    config   *config.Config
+    sessions *SessionService
+    emails   *EmailService
+    policy   *PasswordPolicy
+    twoFA    *TwoFactorService
+    throttle *LoginThrottleService
+    keys     *signing.KeySet
 }
 
 type Claims struct {
-    UserID int `json:"user_id"`
+    UserID    int `json:"user_id"`
+    SessionID int `json:"sid"`
+    // Role is looked up when the token is validated, it is not part of the token
+    Role string `json:"-"`
     jwt.RegisteredClaims
 }
 
-func NewAuthService(db *sql.DB, cfg *config.Config) *AuthService {
+// ChallengeClaims identify a user who passed the password check but still
+// has to provide a two-factor code. They are never accepted as an access token.
+type ChallengeClaims struct {
+    UserID  int    `json:"user_id"`
+    Purpose string `json:"purpose"`
+    jwt.RegisteredClaims
+}
+
+const twoFactorChallengePurpose = "2fa_challenge"
+
+var (
+    // ErrChallengeInvalid is returned for malformed or expired two-factor challenge tokens.
+    ErrChallengeInvalid = errors.New("invalid or expired two-factor challenge")
+    // ErrInvalidCredentials is returned for unknown emails and wrong passwords alike.
+    ErrInvalidCredentials = errors.New("invalid email or password")
+    // ErrAccountSuspended is returned when a suspended user logs in or uses a token.
+    ErrAccountSuspended = errors.New("this account has been suspended")
+)
+
+// dummyPasswordHash is compared against when there is no real hash to check,
+// so unknown emails take as long to reject as wrong passwords.
+const dummyPasswordHash = "$2a$10$WzCdP3onMdgA6nm/zY7J9eh.gsCMqYATNU6TwTg8PVBqVQ0WV2iCq"
+
+func NewAuthService(db *sql.DB, cfg *config.Config, sessionService *SessionService, emailService *EmailService, policy *PasswordPolicy, twoFactorService *TwoFactorService, throttleService *LoginThrottleService, keys *signing.KeySet) *AuthService {
     return &AuthService{
-        db:     db,
-        config: cfg,
+        db:       db,
+        config:   cfg,
+        sessions: sessionService,
+        emails:   emailService,
+        policy:   policy,
+        twoFA:    twoFactorService,
+        throttle: throttleService,
+        keys:     keys,
     }
 }
 
-func (s *AuthService) Register(req models.RegisterRequest) (*models.User, error) {
+// Register creates an account and emails a verification link. An email that
+// is already registered gets a notice instead, and the caller cannot tell the
+// two cases apart, so the endpoint does not reveal which addresses have accounts.
+func (s *AuthService) Register(req models.RegisterRequest, clientIP string) error {
+    if err := s.throttle.CheckRegistration(clientIP); err != nil {
+        return err
+    }
+    if err := s.throttle.RecordRegistration(clientIP); err != nil {
+        return err
+    }
+
+    if err := s.policy.Validate(req.Password); err != nil {
+        return err
+    }
+
+    // Usernames are public, but they are checked before the email so a taken
+    // username fails the same way whether or not the email exists
+    if req.Username != "" {
+        if err := ValidateUsername(req.Username); err != nil {
+            return err
+        }
+        taken, err := usernameTaken(s.db, req.Username, 0)
+        if err != nil {
+            return err
+        }
+        if taken {
+            return ErrUsernameTaken
+        }
+    }
+
+    // Hash password before the lookup so both paths take about as long
+    hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
+    if err != nil {
+        return fmt.Errorf("error hashing password: %w", err)
+    }
+
     // Check if user already exists
-    var existingUser models.User
-    err := s.db.QueryRow("SELECT id FROM users WHERE email = ?", req.Email).Scan(&existingUser.ID)
+    var existingID int
+    err = s.db.QueryRow("SELECT id FROM users WHERE email = ?", req.Email).Scan(&existingID)
     if err == nil {
-        return nil, errors.New("user already exists with this email")
+        if err := s.emails.SendRegistrationNotice(req.Email); err != nil {
+            log.Printf("Failed to send registration notice to user %d: %v", existingID, err)
+        }
+        return nil
     }
     if err != sql.ErrNoRows {
-        return nil, fmt.Errorf("error checking existing user: %w", err)
+        return fmt.Errorf("error checking existing user: %w", err)
     }
 
-    // Hash password
-    hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
-    if err != nil {
-        return nil, fmt.Errorf("error hashing password: %w", err)
+    username := req.Username
+    if username == "" {
+        username, err = uniqueUsername(s.db, req.UserName, req.Email)
+        if err != nil {
+            return err
+        }
     }
 
     // Insert new user
     result, err := s.db.Exec(`
-        INSERT INTO users (user_name, email, password_hash) 
-        VALUES (?, ?, ?)
-    `, req.UserName, req.Email, string(hashedPassword))
+        INSERT INTO users (username, user_name, email, password_hash) 
+        VALUES (?, ?, ?, ?)
+    `, username, req.UserName, req.Email, string(hashedPassword))
     if err != nil {
-        return nil, fmt.Errorf("error creating user: %w", err)
+        return fmt.Errorf("error creating user: %w", err)
     }
 
     userID, err := result.LastInsertId()
     if err != nil {
-        return nil, fmt.Errorf("error getting user ID: %w", err)
+        return fmt.Errorf("error getting user ID: %w", err)
     }
 
-    // Get the created user
-    user, err := s.GetUserByID(int(userID))
-    if err != nil {
-        return nil, fmt.Errorf("error retrieving created user: %w", err)
+    // Registration succeeds even if the verification email cannot be sent;
+    // the user can request a new link later
+    if err := s.emails.SendVerification(int(userID), req.Email); err != nil {
+        log.Printf("Failed to send verification email to user %d: %v", userID, err)
     }
 
-    return user, nil
+    return nil
 }
 
-func (s *AuthService) Login(req models.LoginRequest) (*models.LoginResponse, error) {
+// Login checks the email and password. Throttled emails and IPs get a
+// *ThrottleError before the password is looked at, whether or not the
+// account exists.
+func (s *AuthService) Login(req models.LoginRequest, client models.ClientInfo) (*models.LoginResponse, error) {
+    if err := s.throttle.CheckLogin(req.Email, client.IPAddress); err != nil {
+        return nil, err
+    }
+
     var user models.User
     err := s.db.QueryRow(`
         SELECT id, user_name, email, password_hash 
@@ -79,74 +167,290 @@ func (s *AuthService) Login(req models.LoginRequest) (*models.LoginResponse, err
     `, req.Email).Scan(&user.ID, &user.UserName, &user.Email, &user.PasswordHash)
     
     if err == sql.ErrNoRows {
-        return nil, errors.New("invalid email or password")
+        bcrypt.CompareHashAndPassword([]byte(dummyPasswordHash), []byte(req.Password))
+        return nil, s.loginFailed(req.Email, client.IPAddress)
     }
     if err != nil {
         return nil, fmt.Errorf("error finding user: %w", err)
     }
 
-    // Check password
-    if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(req.Password)); err != nil {
-        return nil, errors.New("invalid email or password")
+    // Check password (accounts created through OIDC have none)
+    passwordHash := user.PasswordHash
+    if passwordHash == "" {
+        passwordHash = dummyPasswordHash
+    }
+    if err := bcrypt.CompareHashAndPassword([]byte(passwordHash), []byte(req.Password)); err != nil || user.PasswordHash == "" {
+        return nil, s.loginFailed(req.Email, client.IPAddress)
     }
 
-    // Generate JWT token
-    token, err := s.GenerateToken(user.ID)
+    response, err := s.startLogin(user.ID, client)
     if err != nil {
-        return nil, fmt.Errorf("error generating token: %w", err)
+        return nil, err
+    }
+
+    // With 2FA the failures are only cleared once the second step succeeds
+    if !response.TwoFactorRequired {
+        if err := s.throttle.RecordLoginSuccess(req.Email); err != nil {
+            log.Printf("Failed to clear login attempts for user %d: %v", user.ID, err)
+        }
+    }
+    return response, nil
+}
+
+// loginFailed records a failed attempt and returns the uniform login error.
+func (s *AuthService) loginFailed(email, clientIP string) error {
+    if err := s.throttle.RecordLoginFailure(email, clientIP); err != nil {
+        return err
+    }
+    return ErrInvalidCredentials
+}
+
+// startLogin is called once the user's primary credential has been checked.
+// Accounts with 2FA get a challenge instead of tokens.
+func (s *AuthService) startLogin(userID int, client models.ClientInfo) (*models.LoginResponse, error) {
+    if _, err := s.accountRole(userID); err != nil {
+        return nil, err
+    }
+
+    twoFactorEnabled, err := s.twoFA.IsEnabled(userID)
+    if err != nil {
+        return nil, err
+    }
+    if twoFactorEnabled {
+        challenge, err := s.generateChallengeToken(userID)
+        if err != nil {
+            return nil, fmt.Errorf("error generating challenge token: %w", err)
+        }
+
+        return &models.LoginResponse{
+            UserID:            userID,
+            Message:           "Two-factor authentication required",
+            TwoFactorRequired: true,
+            ChallengeToken:    challenge,
+        }, nil
+    }
+
+    return s.completeLogin(userID, client)
+}
+
+// CompleteTwoFactorLogin exchanges a challenge token and a TOTP or recovery
+// code for a real session.
+// Wrong codes count as failed logins for the user's email and the client IP.
+func (s *AuthService) CompleteTwoFactorLogin(req models.TwoFactorLoginRequest, client models.ClientInfo) (*models.LoginResponse, error) {
+    token, err := jwt.ParseWithClaims(req.ChallengeToken, &ChallengeClaims{}, func(token *jwt.Token) (interface{}, error) {
+        if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+            return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+        }
+        return []byte(s.config.JWT.Secret), nil
+    })
+    if err != nil {
+        return nil, ErrChallengeInvalid
+    }
+
+    claims, ok := token.Claims.(*ChallengeClaims)
+    if !ok || !token.Valid || claims.Purpose != twoFactorChallengePurpose {
+        return nil, ErrChallengeInvalid
+    }
+
+    var email string
+    if err := s.db.QueryRow("SELECT email FROM users WHERE id = ?", claims.UserID).Scan(&email); err != nil {
+        return nil, fmt.Errorf("error finding user: %w", err)
+    }
+    if err := s.throttle.CheckLogin(email, client.IPAddress); err != nil {
+        return nil, err
+    }
+
+    if err := s.twoFA.Verify(claims.UserID, req.Code, req.RecoveryCode); err != nil {
+        if errors.Is(err, ErrInvalidTwoFactorCode) {
+            if recordErr := s.throttle.RecordLoginFailure(email, client.IPAddress); recordErr != nil {
+                return nil, recordErr
+            }
+        }
+        return nil, err
+    }
+
+    if err := s.throttle.RecordLoginSuccess(email); err != nil {
+        log.Printf("Failed to clear login attempts for user %d: %v", claims.UserID, err)
+    }
+    return s.completeLogin(claims.UserID, client)
+}
+
+// generateChallengeToken signs challenge tokens with the shared secret even
+// when access tokens use a published key, so other services that trust our
+// JWKS can never mistake one for an access token.
+func (s *AuthService) generateChallengeToken(userID int) (string, error) {
+    claims := ChallengeClaims{
+        UserID:  userID,
+        Purpose: twoFactorChallengePurpose,
+        RegisteredClaims: jwt.RegisteredClaims{
+            ExpiresAt: jwt.NewNumericDate(time.Now().Add(s.config.Auth.TwoFactorChallengeTTL)),
+            IssuedAt:  jwt.NewNumericDate(time.Now()),
+            NotBefore: jwt.NewNumericDate(time.Now()),
+        },
+    }
+
+    token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+    return token.SignedString([]byte(s.config.JWT.Secret))
+}
+
+// completeLogin starts a new session and issues its access and refresh tokens.
+func (s *AuthService) completeLogin(userID int, client models.ClientInfo) (*models.LoginResponse, error) {
+    tokens, err := s.issueTokens(userID, client)
+    if err != nil {
+        return nil, err
     }
 
     return &models.LoginResponse{
-        Token:   token,
-        UserID:  user.ID,
-        Message: "Login successful",
+        Token:        tokens.Token,
+        RefreshToken: tokens.RefreshToken,
+        ExpiresIn:    tokens.ExpiresIn,
+        UserID:       userID,
+        Message:      "Login successful",
     }, nil
 }
 
-func (s *AuthService) GenerateToken(userID int) (string, error) {
+// issueTokens creates a session for the user and returns a short-lived access
+// token bound to it together with the first refresh token of the family.
+func (s *AuthService) issueTokens(userID int, client models.ClientInfo) (*models.TokenResponse, error) {
+    tokenID, err := generateRandomToken(16)
+    if err != nil {
+        return nil, err
+    }
+
+    sessionExpiresAt := time.Now().Add(s.config.Session.Duration)
+    sessionID, err := s.sessions.CreateSession(userID, tokenID, sessionExpiresAt, client)
+    if err != nil {
+        return nil, err
+    }
+
+    return s.signTokens(userID, sessionID, tokenID, sessionExpiresAt)
+}
+
+// signTokens signs an access token for the session and issues a refresh token.
+func (s *AuthService) signTokens(userID, sessionID int, tokenID string, sessionExpiresAt time.Time) (*models.TokenResponse, error) {
+    accessToken, err := s.GenerateToken(userID, sessionID, tokenID, time.Now().Add(s.config.JWT.AccessTokenDuration))
+    if err != nil {
+        return nil, fmt.Errorf("error generating token: %w", err)
+    }
+
+    refreshToken, err := s.sessions.IssueRefreshToken(sessionID, userID, sessionExpiresAt)
+    if err != nil {
+        return nil, err
+    }
+
+    return &models.TokenResponse{
+        Token:        accessToken,
+        RefreshToken: refreshToken,
+        ExpiresIn:    int(s.config.JWT.AccessTokenDuration.Seconds()),
+    }, nil
+}
+
+// RefreshToken exchanges a refresh token for a new access token and a new
+// refresh token. The presented refresh token cannot be used again. The
+// session's last seen time and device details are updated.
+func (s *AuthService) RefreshToken(refreshToken string, client models.ClientInfo) (*models.TokenResponse, error) {
+    sessionID, userID, err := s.sessions.ConsumeRefreshToken(refreshToken)
+    if err != nil {
+        return nil, err
+    }
+
+    tokenID, err := generateRandomToken(16)
+    if err != nil {
+        return nil, err
+    }
+
+    sessionExpiresAt := time.Now().Add(s.config.Session.Duration)
+    if err := s.sessions.RotateSessionToken(sessionID, tokenID, sessionExpiresAt, client); err != nil {
+        return nil, err
+    }
+
+    return s.signTokens(userID, sessionID, tokenID, sessionExpiresAt)
+}
+
+func (s *AuthService) GenerateToken(userID, sessionID int, tokenID string, expiresAt time.Time) (string, error) {
     claims := Claims{
-        UserID: userID,
+        UserID:    userID,
+        SessionID: sessionID,
         RegisteredClaims: jwt.RegisteredClaims{
-            ExpiresAt: jwt.NewNumericDate(time.Now().Add(s.config.Session.Duration)),
+            ID:        tokenID,
+            ExpiresAt: jwt.NewNumericDate(expiresAt),
             IssuedAt:  jwt.NewNumericDate(time.Now()),
             NotBefore: jwt.NewNumericDate(time.Now()),
         },
     }
 
-    token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-    return token.SignedString([]byte(s.config.JWT.Secret))
+    return s.keys.Sign(claims)
 }
 
-func (s *AuthService) ValidateToken(tokenString string) (int, error) {
-    token, err := jwt.ParseWithClaims(tokenString, &Claims{}, func(token *jwt.Token) (interface{}, error) {
-        if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-            return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
-        }
-        return []byte(s.config.JWT.Secret), nil
-    })
+// ValidateToken verifies the token signature against the configured keys and
+// checks that its session is still active.
+func (s *AuthService) ValidateToken(tokenString string) (*Claims, error) {
+    token, err := jwt.ParseWithClaims(tokenString, &Claims{}, s.keys.Keyfunc)
 
     if err != nil {
-        return 0, err
+        return nil, err
+    }
+
+    claims, ok := token.Claims.(*Claims)
+    if !ok || !token.Valid {
+        return nil, errors.New("invalid token")
+    }
+
+    if err := s.sessions.ValidateSession(claims.SessionID, claims.UserID, claims.ID); err != nil {
+        return nil, err
     }
 
-    if claims, ok := token.Claims.(*Claims); ok && token.Valid {
-        return claims.UserID, nil
+    claims.Role, err = s.accountRole(claims.UserID)
+    if err != nil {
+        return nil, err
     }
 
-    return 0, errors.New("invalid token")
+    return claims, nil
+}
+
+// accountRole returns the user's role, or ErrAccountSuspended if the account
+// is suspended.
+func (s *AuthService) accountRole(userID int) (string, error) {
+    var (
+        role      string
+        suspended bool
+    )
+    err := s.db.QueryRow(`
+        SELECT role, suspended_at IS NOT NULL FROM users WHERE id = ?
+    `, userID).Scan(&role, &suspended)
+
+    if err == sql.ErrNoRows {
+        return "", ErrSessionInvalid
+    }
+    if err != nil {
+        return "", fmt.Errorf("error finding user: %w", err)
+    }
+    if suspended {
+        return "", ErrAccountSuspended
+    }
+
+    return role, nil
+}
+
+// Logout revokes the session the current token was issued for.
+func (s *AuthService) Logout(userID, sessionID int) error {
+    return s.sessions.RevokeSession(sessionID, userID)
 }
 
 func (s *AuthService) GetUserByID(id int) (*models.User, error) {
     var user models.User
     err := s.db.QueryRow(`
-        SELECT id, user_name, email, profile_image, created_at, updated_at 
+        SELECT id, username, user_name, email, role, profile_image, email_verified_at IS NOT NULL, created_at, updated_at 
         FROM users 
         WHERE id = ?
     `, id).Scan(
         &user.ID, 
+        &user.Username, 
         &user.UserName, 
         &user.Email, 
+        &user.Role, 
         &user.ProfileImage, 
+        &user.EmailVerified, 
         &user.CreatedAt, 
         &user.UpdatedAt,
     )
@@ -164,10 +468,10 @@ func (s *AuthService) GetUserByID(id int) (*models.User, error) {
 func (s *AuthService) GetUserProfile(id int) (*models.UserProfile, error) {
     var profile models.UserProfile
     err := s.db.QueryRow(`
-        SELECT id, user_name, email, profile_image 
+        SELECT id, username, user_name, email, role, bio, profile_image, email_verified_at IS NOT NULL 
         FROM users 
         WHERE id = ?
-    `, id).Scan(&profile.ID, &profile.UserName, &profile.Email, &profile.ProfileImage)
+    `, id).Scan(&profile.ID, &profile.Username, &profile.UserName, &profile.Email, &profile.Role, &profile.Bio, &profile.ProfileImage, &profile.EmailVerified)
 
     if err == sql.ErrNoRows {
         return nil, errors.New("user not found")
@@ -197,6 +501,26 @@ func (s *AuthService) UpdateProfile(userID int, req models.UpdateProfileRequest)
         fmt.Printf("UpdateProfile - Updating username to: %s\n", *req.UserName)
     }
 
+    if req.Username != nil {
+        if err := ValidateUsername(*req.Username); err != nil {
+            return nil, err
+        }
+        taken, err := usernameTaken(s.db, *req.Username, userID)
+        if err != nil {
+            return nil, err
+        }
+        if taken {
+            return nil, ErrUsernameTaken
+        }
+        setParts = append(setParts, "username = ?")
+        args = append(args, *req.Username)
+    }
+
+    if req.Bio != nil {
+        setParts = append(setParts, "bio = ?")
+        args = append(args, utils.SanitizeString(*req.Bio))
+    }
+
     if req.ProfileImage != nil {
         setParts = append(setParts, "profile_image = ?")
         args = append(args, *req.ProfileImage)
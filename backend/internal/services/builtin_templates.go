@@ -0,0 +1,167 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"backend/internal/models"
+)
+
+// Element types and relationship styles used by the editor
+const (
+	elementCharacter    = "circle"
+	elementRelationship = "relationship"
+
+	relationshipGeneric = "generic"
+	relationshipChildOf = "child-of"
+)
+
+// builtinTemplate is a template offered to every user.
+type builtinTemplate struct {
+	id          string
+	name        string
+	description string
+	elements    []models.Element
+}
+
+func (t builtinTemplate) summary() models.ProjectTemplate {
+	template := models.ProjectTemplate{
+		ID:          t.id,
+		Name:        t.name,
+		Description: t.description,
+		BuiltIn:     true,
+	}
+	for _, element := range t.elements {
+		switch element.Type {
+		case elementCharacter:
+			template.CharacterCount++
+		case elementRelationship:
+			template.RelationshipCount++
+		}
+	}
+	return template
+}
+
+func (t builtinTemplate) projectData() (json.RawMessage, error) {
+	now := time.Now()
+	data, err := json.Marshal(models.ProjectData{
+		Elements: t.elements,
+		Metadata: models.Metadata{
+			Version:   "1.0",
+			CreatedAt: now,
+			UpdatedAt: now,
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error encoding template: %w", err)
+	}
+	return data, nil
+}
+
+var builtinTemplates = []builtinTemplate{
+	{
+		id:          "family-tree",
+		name:        "Three-generation family tree",
+		description: "Grandparents, their children and grandchildren, linked by parent relationships.",
+		elements: []models.Element{
+			character("grandfather", "Grandfather", "supporting", 250, 80),
+			character("grandmother", "Grandmother", "supporting", 450, 80),
+			character("father", "Father", "supporting", 200, 260),
+			character("mother", "Mother", "supporting", 400, 260),
+			character("aunt", "Aunt", "neutral", 600, 260),
+			character("hero", "Protagonist", "protagonist", 250, 440),
+			character("sibling", "Sibling", "supporting", 450, 440),
+			relationship("r1", "grandfather", "grandmother", relationshipGeneric, "married"),
+			relationship("r2", "father", "grandfather", relationshipChildOf, "child of"),
+			relationship("r3", "aunt", "grandmother", relationshipChildOf, "child of"),
+			relationship("r4", "father", "mother", relationshipGeneric, "married"),
+			relationship("r5", "hero", "father", relationshipChildOf, "child of"),
+			relationship("r6", "sibling", "mother", relationshipChildOf, "child of"),
+		},
+	},
+	{
+		id:          "royal-court",
+		name:        "Royal court",
+		description: "A monarch surrounded by heirs, advisors and a rival for the throne.",
+		elements: []models.Element{
+			character("king", "The King", "neutral", 350, 80),
+			character("queen", "The Queen", "supporting", 550, 80),
+			character("heir", "Crown Prince", "protagonist", 450, 260),
+			character("advisor", "Royal Advisor", "supporting", 150, 200),
+			character("general", "General", "supporting", 150, 380),
+			character("usurper", "Scheming Duke", "antagonist", 700, 300),
+			character("spy", "Court Spy", "neutral", 700, 460),
+			relationship("r1", "king", "queen", relationshipGeneric, "married"),
+			relationship("r2", "heir", "king", relationshipChildOf, "child of"),
+			relationship("r3", "advisor", "king", relationshipGeneric, "serves"),
+			relationship("r4", "general", "king", relationshipGeneric, "sworn to"),
+			relationship("r5", "usurper", "king", relationshipGeneric, "covets the throne of"),
+			relationship("r6", "spy", "usurper", relationshipGeneric, "reports to"),
+		},
+	},
+	{
+		id:          "rival-factions",
+		name:        "Protagonist vs antagonist factions",
+		description: "Two opposing sides, each with a leader and allies.",
+		elements: []models.Element{
+			character("hero", "Hero", "protagonist", 200, 200),
+			character("mentor", "Mentor", "supporting", 80, 80),
+			character("ally", "Ally", "supporting", 80, 340),
+			character("villain", "Villain", "antagonist", 600, 200),
+			character("lieutenant", "Lieutenant", "antagonist", 720, 80),
+			character("henchman", "Henchman", "antagonist", 720, 340),
+			character("turncoat", "Double Agent", "neutral", 400, 400),
+			relationship("r1", "mentor", "hero", relationshipGeneric, "trains"),
+			relationship("r2", "ally", "hero", relationshipGeneric, "fights alongside"),
+			relationship("r3", "lieutenant", "villain", relationshipGeneric, "serves"),
+			relationship("r4", "henchman", "villain", relationshipGeneric, "serves"),
+			relationship("r5", "hero", "villain", relationshipGeneric, "enemies"),
+			relationship("r6", "turncoat", "hero", relationshipGeneric, "secretly betrays"),
+		},
+	},
+}
+
+// character returns a character element sized like the ones the editor adds.
+func character(id, name, characterType string, x, y float64) models.Element {
+	size := 80.0
+	fontColor := "#000000"
+	fontSize := 16
+	details := ""
+	age := ""
+	return models.Element{
+		ID:            id,
+		Type:          elementCharacter,
+		X:             x,
+		Y:             y,
+		Width:         &size,
+		Height:        &size,
+		Color:         "#000000",
+		FontColor:     &fontColor,
+		FontSize:      &fontSize,
+		Text:          name,
+		CharacterType: &characterType,
+		Details:       &details,
+		Age:           &age,
+	}
+}
+
+// relationship returns a relationship element styled like the editor's.
+// child-of relationships point from the child to the parent.
+func relationship(id, sourceID, targetID, relationshipType, text string) models.Element {
+	color := "#1677ff"
+	directed := relationshipType == relationshipChildOf
+	if directed {
+		color = "#fa541c"
+	}
+	return models.Element{
+		ID:               id,
+		Type:             elementRelationship,
+		Color:            color,
+		Text:             text,
+		SourceID:         &sourceID,
+		TargetID:         &targetID,
+		RelationshipType: &relationshipType,
+		Directed:         &directed,
+	}
+}
@@ -0,0 +1,329 @@
+package services
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"backend/internal/models"
+	"backend/pkg/utils"
+)
+
+var (
+	// ErrCommentNotFound is returned for comments that do not exist, were
+	// deleted or belong to another project.
+	ErrCommentNotFound = errors.New("comment not found")
+	// ErrCommentForbidden is returned when the user may not change a comment.
+	ErrCommentForbidden = errors.New("you cannot change this comment")
+	// ErrCommentEmpty is returned when nothing is left of a comment after
+	// sanitising it.
+	ErrCommentEmpty = errors.New("comment cannot be empty")
+	// ErrElementNotFound is returned when anchoring a comment to an element
+	// that is not in the diagram.
+	ErrElementNotFound = errors.New("element not found in this project")
+)
+
+// CommentService manages comment threads on projects.
+type CommentService struct {
+	db       *sql.DB
+	projects *ProjectService
+}
+
+func NewCommentService(db *sql.DB, projectService *ProjectService) *CommentService {
+	return &CommentService{
+		db:       db,
+		projects: projectService,
+	}
+}
+
+// ListComments returns the comment threads of a project the user can see,
+// oldest first. userID is 0 for anonymous readers.
+func (s *CommentService) ListComments(projectID, userID int) ([]models.Comment, error) {
+	project, err := s.visibleProject(projectID, userID)
+	if err != nil {
+		return nil, err
+	}
+	elements := commentElements(project)
+
+	rows, err := s.db.Query(`
+        SELECT c.id, c.parent_id, c.element_id, c.body, c.user_id, u.user_name, u.username,
+               c.deleted_at IS NOT NULL, c.edited_at, c.created_at
+        FROM project_comments c
+        JOIN users u ON c.user_id = u.id
+        WHERE c.project_id = ?
+        ORDER BY c.created_at, c.id
+    `, projectID)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching comments: %w", err)
+	}
+	defer rows.Close()
+
+	comments := []*models.Comment{}
+	byID := map[int]*models.Comment{}
+	for rows.Next() {
+		comment := &models.Comment{ProjectID: projectID}
+		err := rows.Scan(
+			&comment.ID,
+			&comment.ParentID,
+			&comment.ElementID,
+			&comment.Body,
+			&comment.UserID,
+			&comment.AuthorName,
+			&comment.AuthorUsername,
+			&comment.Deleted,
+			&comment.EditedAt,
+			&comment.CreatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning comment: %w", err)
+		}
+		if comment.Deleted {
+			comment.Body, comment.UserID, comment.AuthorName, comment.AuthorUsername = "", 0, "", ""
+		}
+		comment.Element = anchorElement(elements, comment.ElementID)
+		comments = append(comments, comment)
+		byID[comment.ID] = comment
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error fetching comments: %w", err)
+	}
+
+	children := map[int][]*models.Comment{}
+	roots := []*models.Comment{}
+	for _, comment := range comments {
+		if comment.ParentID != nil && byID[*comment.ParentID] != nil {
+			children[*comment.ParentID] = append(children[*comment.ParentID], comment)
+		} else {
+			roots = append(roots, comment)
+		}
+	}
+
+	return buildThreads(roots, children), nil
+}
+
+// buildThreads nests replies under their parents and drops deleted comments
+// that have no replies left.
+func buildThreads(comments []*models.Comment, children map[int][]*models.Comment) []models.Comment {
+	threads := []models.Comment{}
+	for _, comment := range comments {
+		comment.Replies = buildThreads(children[comment.ID], children)
+		if comment.Deleted && len(comment.Replies) == 0 {
+			continue
+		}
+		threads = append(threads, *comment)
+	}
+	return threads
+}
+
+// CreateComment adds a comment, or a reply when ParentID is set. Replies are
+// anchored to the same element as the comment they answer.
+func (s *CommentService) CreateComment(projectID, userID int, req models.CreateCommentRequest) (*models.Comment, error) {
+	project, err := s.visibleProject(projectID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	body := utils.SanitizeMarkdown(req.Body)
+	if body == "" {
+		return nil, ErrCommentEmpty
+	}
+
+	elementID := req.ElementID
+	if req.ParentID != nil {
+		parent, err := s.getComment(projectID, *req.ParentID)
+		if err != nil {
+			return nil, err
+		}
+		elementID = parent.elementID
+	} else if elementID != nil && *elementID != "" {
+		if _, ok := commentElements(project)[*elementID]; !ok {
+			return nil, ErrElementNotFound
+		}
+	} else {
+		elementID = nil
+	}
+
+	result, err := s.db.Exec(`
+        INSERT INTO project_comments (project_id, user_id, parent_id, element_id, body)
+        VALUES (?, ?, ?, ?, ?)
+    `, projectID, userID, req.ParentID, elementID, body)
+	if err != nil {
+		return nil, fmt.Errorf("error creating comment: %w", err)
+	}
+	commentID, err := result.LastInsertId()
+	if err != nil {
+		return nil, fmt.Errorf("error creating comment: %w", err)
+	}
+
+	return s.loadComment(project, int(commentID))
+}
+
+// UpdateComment changes the body of the user's own comment.
+func (s *CommentService) UpdateComment(projectID, commentID, userID int, req models.UpdateCommentRequest) (*models.Comment, error) {
+	project, err := s.visibleProject(projectID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	comment, err := s.getComment(projectID, commentID)
+	if err != nil {
+		return nil, err
+	}
+	if comment.userID != userID {
+		return nil, ErrCommentForbidden
+	}
+
+	body := utils.SanitizeMarkdown(req.Body)
+	if body == "" {
+		return nil, ErrCommentEmpty
+	}
+
+	_, err = s.db.Exec(`
+        UPDATE project_comments SET body = ?, edited_at = NOW() WHERE id = ?
+    `, body, commentID)
+	if err != nil {
+		return nil, fmt.Errorf("error updating comment: %w", err)
+	}
+
+	return s.loadComment(project, commentID)
+}
+
+// DeleteComment removes a comment. Authors can delete their own comments and
+// project owners any comment on their project. The row is kept so that
+// replies stay in their thread.
+func (s *CommentService) DeleteComment(projectID, commentID, userID int) error {
+	project, err := s.visibleProject(projectID, userID)
+	if err != nil {
+		return err
+	}
+
+	comment, err := s.getComment(projectID, commentID)
+	if err != nil {
+		return err
+	}
+	if comment.userID != userID && project.Role != models.ProjectRoleOwner {
+		return ErrCommentForbidden
+	}
+
+	_, err = s.db.Exec(`
+        UPDATE project_comments SET body = '', deleted_at = NOW() WHERE id = ?
+    `, commentID)
+	if err != nil {
+		return fmt.Errorf("error deleting comment: %w", err)
+	}
+	return nil
+}
+
+// visibleProject returns a project the user is a member of, with their role,
+// or a public or unlisted one.
+func (s *CommentService) visibleProject(projectID, userID int) (*models.Project, error) {
+	if userID != 0 {
+		if project, err := s.projects.GetProjectByID(projectID, userID); err == nil {
+			return project, nil
+		}
+	}
+	if project := s.projects.GetPublicProjectByID(projectID); project != nil {
+		return project, nil
+	}
+	return nil, ErrProjectNotFound
+}
+
+// storedComment is the part of a comment needed to check changes to it
+type storedComment struct {
+	userID    int
+	elementID *string
+}
+
+// getComment finds a comment on the project that has not been deleted.
+func (s *CommentService) getComment(projectID, commentID int) (*storedComment, error) {
+	var comment storedComment
+	err := s.db.QueryRow(`
+        SELECT user_id, element_id FROM project_comments
+        WHERE id = ? AND project_id = ? AND deleted_at IS NULL
+    `, commentID, projectID).Scan(&comment.userID, &comment.elementID)
+	if err == sql.ErrNoRows {
+		return nil, ErrCommentNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error finding comment: %w", err)
+	}
+	return &comment, nil
+}
+
+// loadComment reads back a single comment after it was written.
+func (s *CommentService) loadComment(project *models.Project, commentID int) (*models.Comment, error) {
+	comment := models.Comment{ProjectID: project.ID, Replies: []models.Comment{}}
+	err := s.db.QueryRow(`
+        SELECT c.id, c.parent_id, c.element_id, c.body, c.user_id, u.user_name, u.username, c.edited_at, c.created_at
+        FROM project_comments c
+        JOIN users u ON c.user_id = u.id
+        WHERE c.id = ?
+    `, commentID).Scan(
+		&comment.ID,
+		&comment.ParentID,
+		&comment.ElementID,
+		&comment.Body,
+		&comment.UserID,
+		&comment.AuthorName,
+		&comment.AuthorUsername,
+		&comment.EditedAt,
+		&comment.CreatedAt,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching comment: %w", err)
+	}
+
+	comment.Element = anchorElement(commentElements(project), comment.ElementID)
+	return &comment, nil
+}
+
+// commentElements returns the elements of a project that comments can be
+// anchored to, by ID. Hidden elements are left out for readers who are not
+// members.
+func commentElements(project *models.Project) map[string]models.CommentElement {
+	elements := map[string]models.CommentElement{}
+	var data models.ProjectData
+	if err := json.Unmarshal(project.ProjectData, &data); err != nil {
+		return elements
+	}
+
+	names := map[string]string{}
+	for _, element := range data.Elements {
+		if element.Type == elementCharacter && (!element.Hidden || project.Role != "") {
+			names[element.ID] = element.Text
+		}
+	}
+
+	for _, element := range data.Elements {
+		if element.Hidden && project.Role == "" {
+			continue
+		}
+		switch element.Type {
+		case elementCharacter:
+			elements[element.ID] = models.CommentElement{Type: "character", Label: element.Text}
+		case elementRelationship:
+			label := element.Text
+			if label == "" && element.SourceID != nil && element.TargetID != nil {
+				label = names[*element.SourceID] + " → " + names[*element.TargetID]
+			}
+			elements[element.ID] = models.CommentElement{Type: "relationship", Label: label}
+		default:
+			elements[element.ID] = models.CommentElement{Type: element.Type, Label: element.Text}
+		}
+	}
+	return elements
+}
+
+// anchorElement describes the element a comment is anchored to, marking it
+// missing once it has left the diagram.
+func anchorElement(elements map[string]models.CommentElement, elementID *string) *models.CommentElement {
+	if elementID == nil {
+		return nil
+	}
+	element, ok := elements[*elementID]
+	if !ok {
+		return &models.CommentElement{Missing: true}
+	}
+	return &element
+}
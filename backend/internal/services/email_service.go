@@ -0,0 +1,226 @@
+package services
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"backend/internal/config"
+	"backend/internal/mailer"
+	"backend/internal/models"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+var (
+	// ErrVerificationTokenInvalid is returned for unknown, used or expired verification tokens.
+	ErrVerificationTokenInvalid = errors.New("invalid or expired verification token")
+	// ErrEmailAlreadyVerified is returned when resending for an already verified address.
+	ErrEmailAlreadyVerified = errors.New("email address is already verified")
+	// ErrEmailTaken is returned when the new address belongs to another account.
+	ErrEmailTaken = errors.New("email address is already in use")
+	// ErrInvalidPassword is returned when the current password does not match.
+	ErrInvalidPassword = errors.New("current password is incorrect")
+)
+
+// EmailService verifies users' email addresses and confirms email changes.
+type EmailService struct {
+	db     *sql.DB
+	config *config.Config
+	mailer mailer.Mailer
+}
+
+func NewEmailService(db *sql.DB, cfg *config.Config, m mailer.Mailer) *EmailService {
+	return &EmailService{
+		db:     db,
+		config: cfg,
+		mailer: m,
+	}
+}
+
+// SendVerification emails a verification link for the given address. The
+// address is the user's current email, or the new one during an email change.
+func (s *EmailService) SendVerification(userID int, email string) error {
+	token, err := generateRandomToken(32)
+	if err != nil {
+		return err
+	}
+
+	// Only the most recent link for this address stays valid, so a resend
+	// does not cancel a pending email change and vice versa
+	_, err = s.db.Exec(`
+        UPDATE email_verifications
+        SET used_at = NOW()
+        WHERE user_id = ? AND email = ? AND used_at IS NULL
+    `, userID, email)
+	if err != nil {
+		return fmt.Errorf("error invalidating previous verification tokens: %w", err)
+	}
+
+	_, err = s.db.Exec(`
+        INSERT INTO email_verifications (user_id, email, token_hash, expires_at)
+        VALUES (?, ?, ?, ?)
+    `, userID, email, hashToken(token), time.Now().Add(s.config.Auth.EmailVerificationTTL))
+	if err != nil {
+		return fmt.Errorf("error creating verification token: %w", err)
+	}
+
+	link := fmt.Sprintf("%s/verify-email?token=%s", s.config.Server.FrontendURL, token)
+	return s.mailer.Send(mailer.Message{
+		To:      email,
+		Subject: "Verify your NovelSync email address",
+		Body: fmt.Sprintf("Please confirm this email address for your NovelSync account.\n\n"+
+			"Open the link below within %s:\n\n%s\n\n"+
+			"If you did not expect this email, you can ignore it.\n",
+			s.config.Auth.EmailVerificationTTL, link),
+	})
+}
+
+// SendRegistrationNotice tells the owner of an address that someone tried
+// to sign up with it, in place of the verification link a new account gets.
+func (s *EmailService) SendRegistrationNotice(email string) error {
+	frontendURL := s.config.Server.FrontendURL
+	return s.mailer.Send(mailer.Message{
+		To:      email,
+		Subject: "You already have a NovelSync account",
+		Body: fmt.Sprintf("Someone tried to create a NovelSync account with this email address, "+
+			"but an account already exists.\n\n"+
+			"If it was you, log in at %s/login or reset your password at %s/forgot-password.\n\n"+
+			"If it was not you, you can ignore this email; your account has not changed.\n",
+			frontendURL, frontendURL),
+	})
+}
+
+// ResendVerification sends a new verification link for the user's current email.
+func (s *EmailService) ResendVerification(userID int) error {
+	var (
+		email      string
+		verifiedAt sql.NullTime
+	)
+	err := s.db.QueryRow(`
+        SELECT email, email_verified_at
+        FROM users
+        WHERE id = ?
+    `, userID).Scan(&email, &verifiedAt)
+
+	if err == sql.ErrNoRows {
+		return errors.New("user not found")
+	}
+	if err != nil {
+		return fmt.Errorf("error finding user: %w", err)
+	}
+	if verifiedAt.Valid {
+		return ErrEmailAlreadyVerified
+	}
+
+	return s.SendVerification(userID, email)
+}
+
+// VerifyEmail consumes a verification token. If the token was issued for a
+// different address than the user's current one, the email is switched to it.
+func (s *EmailService) VerifyEmail(token string) error {
+	var (
+		verificationID, userID int
+		email                  string
+		expiresAt              time.Time
+		usedAt                 sql.NullTime
+	)
+	err := s.db.QueryRow(`
+        SELECT id, user_id, email, expires_at, used_at
+        FROM email_verifications
+        WHERE token_hash = ?
+    `, hashToken(token)).Scan(&verificationID, &userID, &email, &expiresAt, &usedAt)
+
+	if err == sql.ErrNoRows {
+		return ErrVerificationTokenInvalid
+	}
+	if err != nil {
+		return fmt.Errorf("error finding verification token: %w", err)
+	}
+	if usedAt.Valid || time.Now().After(expiresAt) {
+		return ErrVerificationTokenInvalid
+	}
+
+	var existingID int
+	err = s.db.QueryRow("SELECT id FROM users WHERE email = ? AND id != ?", email, userID).Scan(&existingID)
+	if err == nil {
+		return ErrEmailTaken
+	}
+	if err != sql.ErrNoRows {
+		return fmt.Errorf("error checking existing user: %w", err)
+	}
+
+	result, err := s.db.Exec(`
+        UPDATE email_verifications
+        SET used_at = NOW()
+        WHERE id = ? AND used_at IS NULL
+    `, verificationID)
+	if err != nil {
+		return fmt.Errorf("error consuming verification token: %w", err)
+	}
+	if affected, err := result.RowsAffected(); err == nil && affected == 0 {
+		return ErrVerificationTokenInvalid
+	}
+
+	_, err = s.db.Exec(`
+        UPDATE users
+        SET email = ?, email_verified_at = NOW(), updated_at = NOW()
+        WHERE id = ?
+    `, email, userID)
+	if err != nil {
+		return fmt.Errorf("error verifying email: %w", err)
+	}
+
+	// Links for other addresses must not switch the email back
+	_, err = s.db.Exec(`
+        UPDATE email_verifications
+        SET used_at = NOW()
+        WHERE user_id = ? AND used_at IS NULL
+    `, userID)
+	if err != nil {
+		return fmt.Errorf("error invalidating other verification tokens: %w", err)
+	}
+
+	return nil
+}
+
+// RequestEmailChange sends a verification link to the new address. The
+// account keeps its current email until the link is opened.
+func (s *EmailService) RequestEmailChange(userID int, req models.ChangeEmailRequest) error {
+	var passwordHash string
+	err := s.db.QueryRow("SELECT password_hash FROM users WHERE id = ?", userID).Scan(&passwordHash)
+	if err == sql.ErrNoRows {
+		return errors.New("user not found")
+	}
+	if err != nil {
+		return fmt.Errorf("error finding user: %w", err)
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(passwordHash), []byte(req.Password)); err != nil {
+		return ErrInvalidPassword
+	}
+
+	var existingID int
+	err = s.db.QueryRow("SELECT id FROM users WHERE email = ?", req.NewEmail).Scan(&existingID)
+	if err == nil {
+		return ErrEmailTaken
+	}
+	if err != sql.ErrNoRows {
+		return fmt.Errorf("error checking existing user: %w", err)
+	}
+
+	// Only the latest requested change stays pending; links for the current
+	// address are left alone
+	_, err = s.db.Exec(`
+        UPDATE email_verifications v
+        JOIN users u ON v.user_id = u.id
+        SET v.used_at = NOW()
+        WHERE v.user_id = ? AND v.used_at IS NULL AND v.email <> u.email
+    `, userID)
+	if err != nil {
+		return fmt.Errorf("error invalidating previous email changes: %w", err)
+	}
+
+	return s.SendVerification(userID, req.NewEmail)
+}
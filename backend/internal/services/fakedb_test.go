@@ -0,0 +1,175 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// fakeDB is a scripted database/sql driver. Every statement must match the
+// next expectation in order, so tests document the queries a flow runs.
+type fakeDB struct {
+	t            *testing.T
+	mu           sync.Mutex
+	expectations []*expectation
+}
+
+type expectation struct {
+	query   string
+	args    []driver.Value
+	columns []string
+	rows    [][]driver.Value
+	result  driver.Result
+	err     error
+	capture *[]driver.Value
+}
+
+// newFakeDB returns a *sql.DB backed by the script; unmet expectations fail
+// the test when it ends.
+func newFakeDB(t *testing.T) (*sql.DB, *fakeDB) {
+	fake := &fakeDB{t: t}
+	db := sql.OpenDB(fake)
+	t.Cleanup(func() {
+		db.Close()
+		fake.mu.Lock()
+		defer fake.mu.Unlock()
+		for _, e := range fake.expectations {
+			t.Errorf("query was not executed: %s", e.query)
+		}
+	})
+	return db, fake
+}
+
+// expect adds a statement whose normalized SQL contains query
+func (f *fakeDB) expect(query string) *expectation {
+	e := &expectation{query: normalizeQuery(query)}
+	f.mu.Lock()
+	f.expectations = append(f.expectations, e)
+	f.mu.Unlock()
+	return e
+}
+
+// withArgs checks the statement's arguments
+func (e *expectation) withArgs(args ...driver.Value) *expectation {
+	e.args = args
+	return e
+}
+
+// captureArgs stores the statement's arguments in dest
+func (e *expectation) captureArgs(dest *[]driver.Value) *expectation {
+	e.capture = dest
+	return e
+}
+
+// returnRows answers a query; no rows yields sql.ErrNoRows from QueryRow
+func (e *expectation) returnRows(columns []string, rows ...[]driver.Value) *expectation {
+	e.columns = columns
+	e.rows = rows
+	return e
+}
+
+func (e *expectation) returnResult(lastInsertID, rowsAffected int64) *expectation {
+	e.result = fakeResult{lastInsertID, rowsAffected}
+	return e
+}
+
+func (e *expectation) returnError(err error) *expectation {
+	e.err = err
+	return e
+}
+
+func (f *fakeDB) next(query string, args []driver.Value) (*expectation, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	query = normalizeQuery(query)
+	if len(f.expectations) == 0 {
+		f.t.Errorf("unexpected query: %s", query)
+		return nil, errors.New("unexpected query")
+	}
+	e := f.expectations[0]
+	f.expectations = f.expectations[1:]
+
+	if !strings.Contains(query, e.query) {
+		f.t.Errorf("query mismatch\n got: %s\nwant: %s", query, e.query)
+		return nil, errors.New("query mismatch")
+	}
+	if e.args != nil && !reflect.DeepEqual(args, e.args) {
+		f.t.Errorf("argument mismatch for %s\n got: %v\nwant: %v", e.query, args, e.args)
+		return nil, errors.New("argument mismatch")
+	}
+	if e.capture != nil {
+		*e.capture = args
+	}
+	return e, e.err
+}
+
+func normalizeQuery(query string) string {
+	return strings.Join(strings.Fields(query), " ")
+}
+
+func (f *fakeDB) Connect(context.Context) (driver.Conn, error) { return fakeConn{f}, nil }
+func (f *fakeDB) Driver() driver.Driver                        { return nil }
+
+type fakeConn struct{ db *fakeDB }
+
+func (c fakeConn) Prepare(query string) (driver.Stmt, error) { return fakeStmt{c.db, query}, nil }
+func (c fakeConn) Close() error                              { return nil }
+func (c fakeConn) Begin() (driver.Tx, error) {
+	return nil, errors.New("transactions are not supported")
+}
+
+type fakeStmt struct {
+	db    *fakeDB
+	query string
+}
+
+func (s fakeStmt) Close() error  { return nil }
+func (s fakeStmt) NumInput() int { return -1 }
+
+func (s fakeStmt) Exec(args []driver.Value) (driver.Result, error) {
+	e, err := s.db.next(s.query, args)
+	if err != nil {
+		return nil, err
+	}
+	if e.result == nil {
+		return fakeResult{0, 1}, nil
+	}
+	return e.result, nil
+}
+
+func (s fakeStmt) Query(args []driver.Value) (driver.Rows, error) {
+	e, err := s.db.next(s.query, args)
+	if err != nil {
+		return nil, err
+	}
+	return &fakeRows{columns: e.columns, rows: e.rows}, nil
+}
+
+type fakeResult struct{ lastInsertID, rowsAffected int64 }
+
+func (r fakeResult) LastInsertId() (int64, error) { return r.lastInsertID, nil }
+func (r fakeResult) RowsAffected() (int64, error) { return r.rowsAffected, nil }
+
+type fakeRows struct {
+	columns []string
+	rows    [][]driver.Value
+}
+
+func (r *fakeRows) Columns() []string { return r.columns }
+func (r *fakeRows) Close() error      { return nil }
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if len(r.rows) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.rows[0])
+	r.rows = r.rows[1:]
+	return nil
+}
@@ -0,0 +1,272 @@
+package services
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"backend/internal/models"
+	"backend/pkg/utils"
+)
+
+var (
+	// ErrFolderNotFound is returned for folders that do not exist or belong
+	// to someone else.
+	ErrFolderNotFound = errors.New("folder not found")
+	// ErrFolderCycle is returned when moving a folder into itself or one of
+	// its subfolders.
+	ErrFolderCycle = errors.New("a folder cannot be moved into itself or one of its subfolders")
+)
+
+// FolderService manages the nested folders users organise their projects
+// in. Folders only change how projects are listed for their owner; deleting
+// a folder never deletes projects.
+type FolderService struct {
+	db       *sql.DB
+	projects *ProjectService
+}
+
+func NewFolderService(db *sql.DB, projectService *ProjectService) *FolderService {
+	return &FolderService{
+		db:       db,
+		projects: projectService,
+	}
+}
+
+// ListFolders returns all of the user's folders as a flat list; parent_id
+// links them into a tree.
+func (s *FolderService) ListFolders(userID int) ([]models.Folder, error) {
+	rows, err := s.db.Query(`
+        SELECT f.id, f.name, f.parent_id, COUNT(p.id), f.created_at, f.updated_at
+        FROM folders f
+        LEFT JOIN folder_projects fp ON fp.folder_id = f.id
+        LEFT JOIN projects p ON p.id = fp.project_id AND p.deleted_at IS NULL
+        WHERE f.user_id = ?
+        GROUP BY f.id, f.name, f.parent_id, f.created_at, f.updated_at
+        ORDER BY f.name
+    `, userID)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching folders: %w", err)
+	}
+	defer rows.Close()
+
+	folders := []models.Folder{}
+	for rows.Next() {
+		var folder models.Folder
+		err := rows.Scan(
+			&folder.ID,
+			&folder.Name,
+			&folder.ParentID,
+			&folder.ProjectCount,
+			&folder.CreatedAt,
+			&folder.UpdatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning folder: %w", err)
+		}
+		folders = append(folders, folder)
+	}
+
+	return folders, nil
+}
+
+// CreateFolder creates a folder, at the top level or inside another of the
+// user's folders.
+func (s *FolderService) CreateFolder(userID int, req models.CreateFolderRequest) (*models.Folder, error) {
+	if req.ParentID != nil {
+		if err := checkFolderOwner(s.db, *req.ParentID, userID); err != nil {
+			return nil, err
+		}
+	}
+
+	result, err := s.db.Exec(`
+        INSERT INTO folders (user_id, parent_id, name) VALUES (?, ?, ?)
+    `, userID, req.ParentID, utils.SanitizeString(req.Name))
+	if err != nil {
+		return nil, fmt.Errorf("error creating folder: %w", err)
+	}
+
+	folderID, err := result.LastInsertId()
+	if err != nil {
+		return nil, fmt.Errorf("error getting folder ID: %w", err)
+	}
+
+	return s.getFolder(int(folderID), userID)
+}
+
+// UpdateFolder renames a folder and/or moves it under another parent.
+func (s *FolderService) UpdateFolder(folderID, userID int, req models.UpdateFolderRequest) (*models.Folder, error) {
+	if err := checkFolderOwner(s.db, folderID, userID); err != nil {
+		return nil, err
+	}
+
+	if req.Name != nil {
+		_, err := s.db.Exec("UPDATE folders SET name = ? WHERE id = ?", utils.SanitizeString(*req.Name), folderID)
+		if err != nil {
+			return nil, fmt.Errorf("error renaming folder: %w", err)
+		}
+	}
+
+	if req.MoveToRoot {
+		if _, err := s.db.Exec("UPDATE folders SET parent_id = NULL WHERE id = ?", folderID); err != nil {
+			return nil, fmt.Errorf("error moving folder: %w", err)
+		}
+	} else if req.ParentID != nil {
+		if err := s.checkCanMove(folderID, *req.ParentID, userID); err != nil {
+			return nil, err
+		}
+		if _, err := s.db.Exec("UPDATE folders SET parent_id = ? WHERE id = ?", *req.ParentID, folderID); err != nil {
+			return nil, fmt.Errorf("error moving folder: %w", err)
+		}
+	}
+
+	return s.getFolder(folderID, userID)
+}
+
+// DeleteFolder deletes a folder. Its projects and subfolders move up to the
+// folder's parent, or to the top level.
+func (s *FolderService) DeleteFolder(folderID, userID int) error {
+	var parentID sql.NullInt64
+	err := s.db.QueryRow("SELECT parent_id FROM folders WHERE id = ? AND user_id = ?", folderID, userID).Scan(&parentID)
+	if err == sql.ErrNoRows {
+		return ErrFolderNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("error finding folder: %w", err)
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("error starting transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec("UPDATE folders SET parent_id = ? WHERE parent_id = ?", parentID, folderID); err != nil {
+		return fmt.Errorf("error moving subfolders: %w", err)
+	}
+	if parentID.Valid {
+		_, err = tx.Exec("UPDATE folder_projects SET folder_id = ? WHERE folder_id = ?", parentID, folderID)
+	} else {
+		_, err = tx.Exec("DELETE FROM folder_projects WHERE folder_id = ?", folderID)
+	}
+	if err != nil {
+		return fmt.Errorf("error moving projects: %w", err)
+	}
+	if _, err := tx.Exec("DELETE FROM folders WHERE id = ?", folderID); err != nil {
+		return fmt.Errorf("error deleting folder: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("error deleting folder: %w", err)
+	}
+	return nil
+}
+
+// MoveProject files a project the user can open in one of their folders, or
+// takes it out of any folder when folderID is nil.
+func (s *FolderService) MoveProject(projectID, userID int, folderID *int) error {
+	if _, err := s.projects.ProjectRole(projectID, userID); err != nil {
+		return err
+	}
+
+	if folderID == nil {
+		_, err := s.db.Exec("DELETE FROM folder_projects WHERE user_id = ? AND project_id = ?", userID, projectID)
+		if err != nil {
+			return fmt.Errorf("error moving project: %w", err)
+		}
+		return nil
+	}
+
+	if err := checkFolderOwner(s.db, *folderID, userID); err != nil {
+		return err
+	}
+	_, err := s.db.Exec(`
+        INSERT INTO folder_projects (user_id, project_id, folder_id) VALUES (?, ?, ?)
+        ON DUPLICATE KEY UPDATE folder_id = VALUES(folder_id)
+    `, userID, projectID, *folderID)
+	if err != nil {
+		return fmt.Errorf("error moving project: %w", err)
+	}
+
+	return nil
+}
+
+// checkCanMove verifies the new parent belongs to the user and is not the
+// folder itself or one of its descendants.
+func (s *FolderService) checkCanMove(folderID, parentID, userID int) error {
+	if err := checkFolderOwner(s.db, parentID, userID); err != nil {
+		return err
+	}
+
+	current := sql.NullInt64{Int64: int64(parentID), Valid: true}
+	for current.Valid {
+		if int(current.Int64) == folderID {
+			return ErrFolderCycle
+		}
+		err := s.db.QueryRow("SELECT parent_id FROM folders WHERE id = ?", current.Int64).Scan(&current)
+		if err != nil {
+			return fmt.Errorf("error checking folder tree: %w", err)
+		}
+	}
+
+	return nil
+}
+
+func (s *FolderService) getFolder(folderID, userID int) (*models.Folder, error) {
+	var folder models.Folder
+	err := s.db.QueryRow(`
+        SELECT f.id, f.name, f.parent_id, COUNT(p.id), f.created_at, f.updated_at
+        FROM folders f
+        LEFT JOIN folder_projects fp ON fp.folder_id = f.id
+        LEFT JOIN projects p ON p.id = fp.project_id AND p.deleted_at IS NULL
+        WHERE f.id = ? AND f.user_id = ?
+        GROUP BY f.id, f.name, f.parent_id, f.created_at, f.updated_at
+    `, folderID, userID).Scan(
+		&folder.ID,
+		&folder.Name,
+		&folder.ParentID,
+		&folder.ProjectCount,
+		&folder.CreatedAt,
+		&folder.UpdatedAt,
+	)
+	if err == sql.ErrNoRows {
+		return nil, ErrFolderNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error fetching folder: %w", err)
+	}
+
+	return &folder, nil
+}
+
+func checkFolderOwner(db *sql.DB, folderID, userID int) error {
+	var exists bool
+	err := db.QueryRow("SELECT EXISTS(SELECT 1 FROM folders WHERE id = ? AND user_id = ?)", folderID, userID).Scan(&exists)
+	if err != nil {
+		return fmt.Errorf("error finding folder: %w", err)
+	}
+	if !exists {
+		return ErrFolderNotFound
+	}
+	return nil
+}
+
+// projectFolders maps project IDs to the folder the user filed them in.
+func projectFolders(db *sql.DB, userID int) (map[int]int, error) {
+	rows, err := db.Query("SELECT project_id, folder_id FROM folder_projects WHERE user_id = ?", userID)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching folders: %w", err)
+	}
+	defer rows.Close()
+
+	folders := map[int]int{}
+	for rows.Next() {
+		var projectID, folderID int
+		if err := rows.Scan(&projectID, &folderID); err != nil {
+			return nil, fmt.Errorf("error scanning folder: %w", err)
+		}
+		folders[projectID] = folderID
+	}
+
+	return folders, nil
+}
@@ -0,0 +1,191 @@
+package services
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"backend/internal/config"
+)
+
+// Attempt types stored in auth_attempts
+const (
+	attemptLogin    = "login"
+	attemptRegister = "register"
+)
+
+// ErrTooManyAttempts is returned while an email address or client IP is
+// throttled or locked out.
+var ErrTooManyAttempts = errors.New("too many attempts, please try again later")
+
+// ThrottleError carries how long the caller has to wait. It matches
+// ErrTooManyAttempts with errors.Is.
+type ThrottleError struct {
+	RetryAfter time.Duration
+}
+
+func (e *ThrottleError) Error() string {
+	return ErrTooManyAttempts.Error()
+}
+
+func (e *ThrottleError) Unwrap() error {
+	return ErrTooManyAttempts
+}
+
+// LoginThrottleService records failed logins and registrations and slows
+// down or temporarily locks out email addresses and client IPs that
+// accumulate too many of them. Locks expire on their own.
+type LoginThrottleService struct {
+	db     *sql.DB
+	config *config.Config
+}
+
+func NewLoginThrottleService(db *sql.DB, cfg *config.Config) *LoginThrottleService {
+	return &LoginThrottleService{
+		db:     db,
+		config: cfg,
+	}
+}
+
+// CheckLogin returns a *ThrottleError if the email or IP must wait before
+// trying again. Unknown emails are throttled exactly like real ones.
+func (s *LoginThrottleService) CheckLogin(email, clientIP string) error {
+	cfg := s.config.Auth
+	now := time.Now()
+	since := now.Add(-maxDuration(cfg.LoginAttemptWindow, cfg.LoginLockoutDuration))
+
+	failures, last, err := s.countAttempts(attemptLogin, "email = ?", normalizeEmail(email), since)
+	if err != nil {
+		return err
+	}
+	wait := loginDelay(failures, last, now, cfg.LoginThrottleAfter, cfg.LoginMaxAttempts, cfg.LoginLockoutDuration)
+
+	failures, last, err = s.countAttempts(attemptLogin, "ip_address = ?", clientIP, since)
+	if err != nil {
+		return err
+	}
+	if ipWait := loginDelay(failures, last, now, cfg.LoginIPMaxAttempts, cfg.LoginIPMaxAttempts, cfg.LoginLockoutDuration); ipWait > wait {
+		wait = ipWait
+	}
+
+	if wait > 0 {
+		return &ThrottleError{RetryAfter: wait}
+	}
+	return nil
+}
+
+// RecordLoginFailure stores a failed login (wrong password, unknown email or
+// wrong second factor) and prunes attempts that no longer count.
+func (s *LoginThrottleService) RecordLoginFailure(email, clientIP string) error {
+	return s.recordAttempt(attemptLogin, normalizeEmail(email), clientIP)
+}
+
+// RecordLoginSuccess clears the failures recorded for the email address.
+// Failures from the IP are kept so one valid account cannot reset them.
+func (s *LoginThrottleService) RecordLoginSuccess(email string) error {
+	_, err := s.db.Exec("DELETE FROM auth_attempts WHERE action = ? AND email = ?", attemptLogin, normalizeEmail(email))
+	if err != nil {
+		return fmt.Errorf("error clearing login attempts: %w", err)
+	}
+	return nil
+}
+
+// CheckRegistration limits how many accounts a client IP can try to register
+// within the registration window.
+func (s *LoginThrottleService) CheckRegistration(clientIP string) error {
+	cfg := s.config.Auth
+	now := time.Now()
+
+	attempts, last, err := s.countAttempts(attemptRegister, "ip_address = ?", clientIP, now.Add(-cfg.RegisterAttemptWindow))
+	if err != nil {
+		return err
+	}
+	if attempts < cfg.RegisterIPMaxAttempts {
+		return nil
+	}
+
+	// Conservative: wait until the newest attempt has left the window
+	return &ThrottleError{RetryAfter: last.Add(cfg.RegisterAttemptWindow).Sub(now)}
+}
+
+// RecordRegistration stores a registration attempt, successful or not.
+func (s *LoginThrottleService) RecordRegistration(clientIP string) error {
+	return s.recordAttempt(attemptRegister, "", clientIP)
+}
+
+// countAttempts returns the number of attempts matching the condition since
+// the given time and when the most recent one happened.
+func (s *LoginThrottleService) countAttempts(action, condition, value string, since time.Time) (int, time.Time, error) {
+	var (
+		count int
+		last  sql.NullTime
+	)
+	err := s.db.QueryRow(`
+        SELECT COUNT(*), MAX(created_at)
+        FROM auth_attempts
+        WHERE action = ? AND `+condition+` AND created_at > ?
+    `, action, value, since).Scan(&count, &last)
+	if err != nil {
+		return 0, time.Time{}, fmt.Errorf("error counting attempts: %w", err)
+	}
+
+	return count, last.Time, nil
+}
+
+func (s *LoginThrottleService) recordAttempt(action, email, clientIP string) error {
+	now := time.Now()
+	_, err := s.db.Exec(`
+        INSERT INTO auth_attempts (action, email, ip_address, created_at)
+        VALUES (?, ?, ?, ?)
+    `, action, nullableString(email), clientIP, now)
+	if err != nil {
+		return fmt.Errorf("error recording attempt: %w", err)
+	}
+
+	cfg := s.config.Auth
+	retention := maxDuration(maxDuration(cfg.LoginAttemptWindow, cfg.LoginLockoutDuration), cfg.RegisterAttemptWindow)
+	if _, err := s.db.Exec("DELETE FROM auth_attempts WHERE created_at < ?", now.Add(-retention)); err != nil {
+		return fmt.Errorf("error pruning attempts: %w", err)
+	}
+
+	return nil
+}
+
+// loginDelay returns how long to wait after the last failure. From
+// throttleAfter failures on, the delay doubles with every failure starting at
+// one second; from maxAttempts failures on, the key is locked for lockout.
+func loginDelay(failures int, last, now time.Time, throttleAfter, maxAttempts int, lockout time.Duration) time.Duration {
+	var delay time.Duration
+	switch {
+	case maxAttempts > 0 && failures >= maxAttempts:
+		delay = lockout
+	case throttleAfter > 0 && failures >= throttleAfter:
+		delay = lockout
+		if failures-throttleAfter < 30 {
+			delay = time.Second << uint(failures-throttleAfter)
+		}
+		if delay > lockout {
+			delay = lockout
+		}
+	default:
+		return 0
+	}
+
+	if wait := last.Add(delay).Sub(now); wait > 0 {
+		return wait
+	}
+	return 0
+}
+
+func normalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
+func maxDuration(a, b time.Duration) time.Duration {
+	if a > b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,343 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"backend/internal/config"
+	"backend/internal/models"
+	"backend/internal/oidc"
+)
+
+var (
+	// ErrUnknownProvider is returned for provider names that are not configured.
+	ErrUnknownProvider = errors.New("unknown identity provider")
+	// ErrOIDCStateInvalid is returned when the callback state is unknown or expired.
+	ErrOIDCStateInvalid = errors.New("invalid or expired login state")
+	// ErrIdentityConflict is returned when the provider's email belongs to an
+	// existing account that cannot be linked automatically.
+	ErrIdentityConflict = errors.New("an account with this email already exists, log in and link the provider from your profile")
+	// ErrIdentityLinkedElsewhere is returned when linking an identity that belongs to another user.
+	ErrIdentityLinkedElsewhere = errors.New("this provider account is already linked to another user")
+	// ErrLastLoginMethod is returned when unlinking would leave the account without a way to log in.
+	ErrLastLoginMethod = errors.New("cannot remove the only login method, set a password first")
+	// ErrIdentityNotFound is returned for identities that do not exist or belong to someone else.
+	ErrIdentityNotFound = errors.New("identity not found")
+)
+
+// OIDCService implements login with external OpenID Connect providers and
+// links provider identities to local users.
+type OIDCService struct {
+	db        *sql.DB
+	config    *config.Config
+	auth      *AuthService
+	providers map[string]*oidc.Provider
+}
+
+// NewOIDCService creates a provider client for every configured provider.
+// A nil httpClient uses a default client with a timeout.
+func NewOIDCService(db *sql.DB, cfg *config.Config, authService *AuthService, httpClient *http.Client) *OIDCService {
+	providers := make(map[string]*oidc.Provider, len(cfg.OIDC.Providers))
+	for _, providerConfig := range cfg.OIDC.Providers {
+		redirectURL := fmt.Sprintf("%s/api/auth/oidc/%s/callback", strings.TrimRight(cfg.OIDC.RedirectBaseURL, "/"), providerConfig.Name)
+		providers[providerConfig.Name] = oidc.NewProvider(providerConfig, redirectURL, httpClient)
+	}
+
+	return &OIDCService{
+		db:        db,
+		config:    cfg,
+		auth:      authService,
+		providers: providers,
+	}
+}
+
+// ProviderNames returns the configured provider names in alphabetical order.
+func (s *OIDCService) ProviderNames() []string {
+	names := make([]string, 0, len(s.providers))
+	for name := range s.providers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// StartLogin stores a new state/nonce/PKCE verifier and returns the provider's
+// authorization URL. A non-zero linkUserID links the identity to that user
+// instead of logging in.
+func (s *OIDCService) StartLogin(ctx context.Context, providerName string, linkUserID int) (string, error) {
+	provider, ok := s.providers[providerName]
+	if !ok {
+		return "", ErrUnknownProvider
+	}
+
+	state, err := oidc.GenerateState()
+	if err != nil {
+		return "", err
+	}
+	nonce, err := oidc.GenerateState()
+	if err != nil {
+		return "", err
+	}
+	verifier, err := oidc.GenerateCodeVerifier()
+	if err != nil {
+		return "", err
+	}
+
+	authURL, err := provider.AuthCodeURL(ctx, state, nonce, verifier)
+	if err != nil {
+		return "", err
+	}
+
+	var linkUser interface{}
+	if linkUserID != 0 {
+		linkUser = linkUserID
+	}
+	_, err = s.db.Exec(`
+        INSERT INTO oidc_states (state_hash, provider, nonce, code_verifier, user_id, expires_at)
+        VALUES (?, ?, ?, ?, ?, ?)
+    `, hashToken(state), providerName, nonce, verifier, linkUser, time.Now().Add(s.config.OIDC.StateTTL))
+	if err != nil {
+		return "", fmt.Errorf("error saving login state: %w", err)
+	}
+
+	return authURL, nil
+}
+
+// HandleCallback consumes the state, redeems the code and either logs the
+// user in (creating or linking an account as needed) or links the identity
+// to the user who started the flow.
+func (s *OIDCService) HandleCallback(ctx context.Context, providerName, state, code string, client models.ClientInfo) (*models.OIDCCallbackResult, error) {
+	provider, ok := s.providers[providerName]
+	if !ok {
+		return nil, ErrUnknownProvider
+	}
+
+	var (
+		stateID         int
+		nonce, verifier string
+		linkUserID      sql.NullInt64
+		expiresAt       time.Time
+	)
+	err := s.db.QueryRow(`
+        SELECT id, nonce, code_verifier, user_id, expires_at
+        FROM oidc_states
+        WHERE state_hash = ? AND provider = ?
+    `, hashToken(state), providerName).Scan(&stateID, &nonce, &verifier, &linkUserID, &expiresAt)
+	if err == sql.ErrNoRows {
+		return nil, ErrOIDCStateInvalid
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error finding login state: %w", err)
+	}
+
+	// States are single use
+	if _, err := s.db.Exec("DELETE FROM oidc_states WHERE id = ?", stateID); err != nil {
+		return nil, fmt.Errorf("error consuming login state: %w", err)
+	}
+	if time.Now().After(expiresAt) {
+		return nil, ErrOIDCStateInvalid
+	}
+
+	claims, err := provider.Exchange(ctx, code, verifier, nonce)
+	if err != nil {
+		return nil, err
+	}
+
+	if linkUserID.Valid {
+		if err := s.linkIdentity(int(linkUserID.Int64), providerName, claims); err != nil {
+			return nil, err
+		}
+		return &models.OIDCCallbackResult{Linked: true}, nil
+	}
+
+	userID, err := s.findOrCreateUser(providerName, claims)
+	if err != nil {
+		return nil, err
+	}
+
+	login, err := s.auth.startLogin(userID, client)
+	if err != nil {
+		return nil, err
+	}
+	return &models.OIDCCallbackResult{Login: login}, nil
+}
+
+// ListIdentities returns the provider identities linked to the user.
+func (s *OIDCService) ListIdentities(userID int) ([]models.Identity, error) {
+	rows, err := s.db.Query(`
+        SELECT id, provider, email, created_at, last_login_at
+        FROM user_identities
+        WHERE user_id = ?
+        ORDER BY created_at
+    `, userID)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching identities: %w", err)
+	}
+	defer rows.Close()
+
+	identities := []models.Identity{}
+	for rows.Next() {
+		var identity models.Identity
+		if err := rows.Scan(&identity.ID, &identity.Provider, &identity.Email, &identity.CreatedAt, &identity.LastLoginAt); err != nil {
+			return nil, fmt.Errorf("error scanning identity: %w", err)
+		}
+		identities = append(identities, identity)
+	}
+
+	return identities, nil
+}
+
+// UnlinkIdentity removes a linked identity unless it is the user's only way to log in.
+func (s *OIDCService) UnlinkIdentity(userID, identityID int) error {
+	var (
+		hasPassword   bool
+		identityCount int
+	)
+	err := s.db.QueryRow(`
+        SELECT u.password_hash != '', COUNT(i.id)
+        FROM users u
+        LEFT JOIN user_identities i ON i.user_id = u.id
+        WHERE u.id = ?
+        GROUP BY u.id
+    `, userID).Scan(&hasPassword, &identityCount)
+	if err != nil {
+		return fmt.Errorf("error checking login methods: %w", err)
+	}
+	if !hasPassword && identityCount <= 1 {
+		return ErrLastLoginMethod
+	}
+
+	result, err := s.db.Exec("DELETE FROM user_identities WHERE id = ? AND user_id = ?", identityID, userID)
+	if err != nil {
+		return fmt.Errorf("error unlinking identity: %w", err)
+	}
+	if affected, err := result.RowsAffected(); err == nil && affected == 0 {
+		return ErrIdentityNotFound
+	}
+
+	return nil
+}
+
+func (s *OIDCService) linkIdentity(userID int, providerName string, claims *oidc.IDTokenClaims) error {
+	var ownerID int
+	err := s.db.QueryRow(`
+        SELECT user_id FROM user_identities WHERE provider = ? AND subject = ?
+    `, providerName, claims.Subject).Scan(&ownerID)
+	if err == nil {
+		if ownerID != userID {
+			return ErrIdentityLinkedElsewhere
+		}
+		return nil
+	}
+	if err != sql.ErrNoRows {
+		return fmt.Errorf("error finding identity: %w", err)
+	}
+
+	_, err = s.db.Exec(`
+        INSERT INTO user_identities (user_id, provider, subject, email)
+        VALUES (?, ?, ?, ?)
+    `, userID, providerName, claims.Subject, nullableString(claims.Email))
+	if err != nil {
+		return fmt.Errorf("error linking identity: %w", err)
+	}
+
+	return nil
+}
+
+// findOrCreateUser resolves the local user for a provider identity. Unknown
+// identities are linked to an existing account only when both the provider
+// and the local account have verified the email address, so an unverified
+// sign-up cannot capture the address owner's identity; otherwise a new
+// account is created.
+func (s *OIDCService) findOrCreateUser(providerName string, claims *oidc.IDTokenClaims) (int, error) {
+	var userID int
+	err := s.db.QueryRow(`
+        SELECT user_id FROM user_identities WHERE provider = ? AND subject = ?
+    `, providerName, claims.Subject).Scan(&userID)
+	if err == nil {
+		_, err = s.db.Exec(`
+            UPDATE user_identities SET last_login_at = NOW() WHERE provider = ? AND subject = ?
+        `, providerName, claims.Subject)
+		if err != nil {
+			return 0, fmt.Errorf("error updating identity: %w", err)
+		}
+		return userID, nil
+	}
+	if err != sql.ErrNoRows {
+		return 0, fmt.Errorf("error finding identity: %w", err)
+	}
+
+	if claims.Email == "" {
+		return 0, errors.New("identity provider did not return an email address")
+	}
+
+	var localVerified bool
+	err = s.db.QueryRow(`
+        SELECT id, email_verified_at IS NOT NULL FROM users WHERE email = ?
+    `, claims.Email).Scan(&userID, &localVerified)
+	switch {
+	case err == nil:
+		if !claims.EmailVerified || !localVerified {
+			return 0, ErrIdentityConflict
+		}
+	case err == sql.ErrNoRows:
+		userID, err = s.createUser(claims)
+		if err != nil {
+			return 0, err
+		}
+	default:
+		return 0, fmt.Errorf("error checking existing user: %w", err)
+	}
+
+	if err := s.linkIdentity(userID, providerName, claims); err != nil {
+		return 0, err
+	}
+	return userID, nil
+}
+
+// createUser registers a user without a password; they can set one later
+// through the password reset flow.
+func (s *OIDCService) createUser(claims *oidc.IDTokenClaims) (int, error) {
+	userName := claims.Name
+	if userName == "" {
+		userName = strings.Split(claims.Email, "@")[0]
+	}
+
+	username, err := uniqueUsername(s.db, userName, claims.Email)
+	if err != nil {
+		return 0, err
+	}
+
+	var verifiedAt interface{}
+	if claims.EmailVerified {
+		verifiedAt = time.Now()
+	}
+
+	result, err := s.db.Exec(`
+        INSERT INTO users (username, user_name, email, password_hash, profile_image, email_verified_at)
+        VALUES (?, ?, ?, '', ?, ?)
+    `, username, userName, claims.Email, nullableString(claims.Picture), verifiedAt)
+	if err != nil {
+		return 0, fmt.Errorf("error creating user: %w", err)
+	}
+
+	userID, err := result.LastInsertId()
+	if err != nil {
+		return 0, fmt.Errorf("error getting user ID: %w", err)
+	}
+
+	return int(userID), nil
+}
+
+func nullableString(value string) interface{} {
+	if value == "" {
+		return nil
+	}
+	return value
+}
@@ -0,0 +1,240 @@
+package services
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"backend/internal/config"
+	"backend/internal/models"
+	"backend/internal/oidc"
+	"backend/internal/oidc/oidctest"
+)
+
+var (
+	stateColumns    = []string{"id", "nonce", "code_verifier", "user_id", "expires_at"}
+	identityColumns = []string{"user_id"}
+	userColumns     = []string{"id", "verified"}
+)
+
+func newTestOIDCService(t *testing.T) (*OIDCService, *fakeDB, *oidctest.Issuer) {
+	issuer := oidctest.NewIssuer()
+	t.Cleanup(issuer.Close)
+
+	db, fake := newFakeDB(t)
+	cfg := &config.Config{OIDC: config.OIDCConfig{
+		RedirectBaseURL: "http://localhost:8080",
+		StateTTL:        10 * time.Minute,
+		Providers: []config.OIDCProviderConfig{
+			{Name: "mock", Issuer: issuer.URL, ClientID: "novelsync", Scopes: []string{"openid", "email"}},
+		},
+	}}
+	return NewOIDCService(db, cfg, nil, nil), fake, issuer
+}
+
+// pendingLogin is a started flow the provider has approved
+type pendingLogin struct {
+	state, code, nonce, verifier string
+}
+
+// startLogin runs StartLogin and lets the issuer approve the identity
+func startLogin(t *testing.T, service *OIDCService, fake *fakeDB, issuer *oidctest.Issuer, linkUserID int, identity oidctest.Identity) pendingLogin {
+	t.Helper()
+
+	var stored []driver.Value
+	fake.expect("INSERT INTO oidc_states").captureArgs(&stored)
+	authURL, err := service.StartLogin(context.Background(), "mock", linkUserID)
+	if err != nil {
+		t.Fatalf("StartLogin: %v", err)
+	}
+
+	parsed, _ := url.Parse(authURL)
+	state := parsed.Query().Get("state")
+	if stored[0] != hashToken(state) || stored[1] != "mock" {
+		t.Fatalf("state stored as %v", stored)
+	}
+	if parsed.Query().Get("code_challenge") != oidc.CodeChallenge(stored[3].(string)) {
+		t.Fatal("code challenge does not match the stored verifier")
+	}
+	if linkUserID == 0 && stored[4] != nil || linkUserID != 0 && stored[4] != int64(linkUserID) {
+		t.Fatalf("link user stored as %v", stored[4])
+	}
+
+	code, err := issuer.Authorize(authURL, identity)
+	if err != nil {
+		t.Fatalf("Authorize: %v", err)
+	}
+	return pendingLogin{state: state, code: code, nonce: stored[2].(string), verifier: stored[3].(string)}
+}
+
+// expectState scripts the lookup and consumption of the stored state
+func expectState(fake *fakeDB, login pendingLogin, linkUserID interface{}, expiresAt time.Time) {
+	fake.expect("FROM oidc_states WHERE state_hash = ? AND provider = ?").
+		withArgs(hashToken(login.state), "mock").
+		returnRows(stateColumns, []driver.Value{int64(1), login.nonce, login.verifier, linkUserID, expiresAt})
+	fake.expect("DELETE FROM oidc_states WHERE id = ?").withArgs(int64(1))
+}
+
+func TestHandleCallbackLinksIdentity(t *testing.T) {
+	service, fake, issuer := newTestOIDCService(t)
+	login := startLogin(t, service, fake, issuer, 7, oidctest.Identity{Subject: "alice", Email: "alice@example.com", EmailVerified: true})
+
+	expectState(fake, login, int64(7), time.Now().Add(time.Minute))
+	fake.expect("SELECT user_id FROM user_identities").withArgs("mock", "alice").returnRows(identityColumns)
+	fake.expect("INSERT INTO user_identities").withArgs(int64(7), "mock", "alice", "alice@example.com")
+
+	result, err := service.HandleCallback(context.Background(), "mock", login.state, login.code, models.ClientInfo{})
+	if err != nil {
+		t.Fatalf("HandleCallback: %v", err)
+	}
+	if !result.Linked || result.Login != nil {
+		t.Errorf("expected a linked result, got %+v", result)
+	}
+}
+
+func TestHandleCallbackRejectsIdentityLinkedElsewhere(t *testing.T) {
+	service, fake, issuer := newTestOIDCService(t)
+	login := startLogin(t, service, fake, issuer, 7, oidctest.Identity{Subject: "alice", Email: "alice@example.com"})
+
+	expectState(fake, login, int64(7), time.Now().Add(time.Minute))
+	fake.expect("SELECT user_id FROM user_identities").returnRows(identityColumns, []driver.Value{int64(9)})
+
+	_, err := service.HandleCallback(context.Background(), "mock", login.state, login.code, models.ClientInfo{})
+	if !errors.Is(err, ErrIdentityLinkedElsewhere) {
+		t.Fatalf("expected ErrIdentityLinkedElsewhere, got %v", err)
+	}
+}
+
+func TestHandleCallbackRejectsUnknownState(t *testing.T) {
+	service, fake, _ := newTestOIDCService(t)
+	fake.expect("FROM oidc_states WHERE state_hash = ?").returnRows(stateColumns)
+
+	_, err := service.HandleCallback(context.Background(), "mock", "forged", "code", models.ClientInfo{})
+	if !errors.Is(err, ErrOIDCStateInvalid) {
+		t.Fatalf("expected ErrOIDCStateInvalid, got %v", err)
+	}
+}
+
+func TestHandleCallbackRejectsExpiredState(t *testing.T) {
+	service, fake, issuer := newTestOIDCService(t)
+	login := startLogin(t, service, fake, issuer, 0, oidctest.Identity{Subject: "alice", Email: "alice@example.com"})
+
+	// The state is consumed even though it is rejected
+	expectState(fake, login, nil, time.Now().Add(-time.Minute))
+
+	_, err := service.HandleCallback(context.Background(), "mock", login.state, login.code, models.ClientInfo{})
+	if !errors.Is(err, ErrOIDCStateInvalid) {
+		t.Fatalf("expected ErrOIDCStateInvalid, got %v", err)
+	}
+}
+
+func TestHandleCallbackRejectsInvalidExchange(t *testing.T) {
+	tests := []struct {
+		name     string
+		identity oidctest.Identity
+		tamper   func(*pendingLogin)
+		want     string
+	}{
+		{
+			name:     "code verifier",
+			identity: oidctest.Identity{Subject: "alice"},
+			tamper:   func(login *pendingLogin) { login.verifier, _ = oidc.GenerateCodeVerifier() },
+			want:     "invalid_grant",
+		},
+		{
+			name:     "nonce",
+			identity: oidctest.Identity{Subject: "alice", Nonce: "replayed"},
+			want:     "nonce",
+		},
+		{
+			name:     "audience",
+			identity: oidctest.Identity{Subject: "alice", Audience: "another-client"},
+			want:     "aud",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, fake, issuer := newTestOIDCService(t)
+			login := startLogin(t, service, fake, issuer, 0, tt.identity)
+			if tt.tamper != nil {
+				tt.tamper(&login)
+			}
+			expectState(fake, login, nil, time.Now().Add(time.Minute))
+
+			_, err := service.HandleCallback(context.Background(), "mock", login.state, login.code, models.ClientInfo{})
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("expected an error mentioning %q, got %v", tt.want, err)
+			}
+		})
+	}
+}
+
+func TestHandleCallbackRejectsUnverifiedEmailMatch(t *testing.T) {
+	tests := []struct {
+		name             string
+		providerVerified bool
+		localVerified    bool
+	}{
+		{name: "local account unverified", providerVerified: true, localVerified: false},
+		{name: "provider email unverified", providerVerified: false, localVerified: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, fake, issuer := newTestOIDCService(t)
+			login := startLogin(t, service, fake, issuer, 0, oidctest.Identity{Subject: "alice", Email: "alice@example.com", EmailVerified: tt.providerVerified})
+
+			expectState(fake, login, nil, time.Now().Add(time.Minute))
+			fake.expect("SELECT user_id FROM user_identities").returnRows(identityColumns)
+			fake.expect("FROM users WHERE email = ?").withArgs("alice@example.com").
+				returnRows(userColumns, []driver.Value{int64(3), tt.localVerified})
+
+			_, err := service.HandleCallback(context.Background(), "mock", login.state, login.code, models.ClientInfo{})
+			if !errors.Is(err, ErrIdentityConflict) {
+				t.Fatalf("expected ErrIdentityConflict, got %v", err)
+			}
+		})
+	}
+}
+
+func TestFindOrCreateUserLinksVerifiedAccount(t *testing.T) {
+	service, fake, _ := newTestOIDCService(t)
+	claims := &oidc.IDTokenClaims{Email: "alice@example.com", EmailVerified: true}
+	claims.Subject = "alice"
+
+	fake.expect("SELECT user_id FROM user_identities").returnRows(identityColumns)
+	fake.expect("FROM users WHERE email = ?").returnRows(userColumns, []driver.Value{int64(3), true})
+	fake.expect("SELECT user_id FROM user_identities").returnRows(identityColumns)
+	fake.expect("INSERT INTO user_identities").withArgs(int64(3), "mock", "alice", "alice@example.com")
+
+	userID, err := service.findOrCreateUser("mock", claims)
+	if err != nil {
+		t.Fatalf("findOrCreateUser: %v", err)
+	}
+	if userID != 3 {
+		t.Errorf("expected user 3, got %d", userID)
+	}
+}
+
+func TestFindOrCreateUserUsesLinkedIdentity(t *testing.T) {
+	service, fake, _ := newTestOIDCService(t)
+	claims := &oidc.IDTokenClaims{Email: "changed@example.com"}
+	claims.Subject = "alice"
+
+	// A linked identity wins over the email, whatever its verification state
+	fake.expect("SELECT user_id FROM user_identities").returnRows(identityColumns, []driver.Value{int64(5)})
+	fake.expect("UPDATE user_identities SET last_login_at = NOW()").withArgs("mock", "alice")
+
+	userID, err := service.findOrCreateUser("mock", claims)
+	if err != nil {
+		t.Fatalf("findOrCreateUser: %v", err)
+	}
+	if userID != 5 {
+		t.Errorf("expected user 5, got %d", userID)
+	}
+}
@@ -0,0 +1,82 @@
+package services
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"backend/internal/config"
+	"backend/pkg/utils"
+)
+
+// ErrWeakPassword is returned when a new password does not satisfy the policy.
+var ErrWeakPassword = errors.New("password does not meet the password policy")
+
+// PasswordPolicy validates new passwords against the configured rules and an
+// optional local list of breached passwords.
+type PasswordPolicy struct {
+	rules    utils.PasswordPolicy
+	breached map[string]struct{}
+}
+
+func NewPasswordPolicy(cfg config.AuthConfig) *PasswordPolicy {
+	policy := &PasswordPolicy{
+		rules: utils.PasswordPolicy{
+			MinLength:    cfg.PasswordMinLength,
+			MaxLength:    utils.DefaultPasswordPolicy.MaxLength,
+			RequireUpper: cfg.PasswordRequireUpper,
+			RequireLower: cfg.PasswordRequireLower,
+			RequireDigit: cfg.PasswordRequireDigit,
+		},
+		breached: map[string]struct{}{},
+	}
+
+	if cfg.BreachedPasswordsFile != "" {
+		if err := policy.loadBreachedPasswords(cfg.BreachedPasswordsFile); err != nil {
+			log.Printf("Warning: Failed to load breached password list: %v", err)
+		}
+	}
+
+	return policy
+}
+
+// Validate returns an error wrapping ErrWeakPassword with a user-facing
+// explanation if the password is not acceptable.
+func (p *PasswordPolicy) Validate(password string) error {
+	if ok, message := utils.ValidatePassword(password, p.rules); !ok {
+		return fmt.Errorf("%w: %s", ErrWeakPassword, message)
+	}
+
+	if _, found := p.breached[strings.ToLower(password)]; found {
+		return fmt.Errorf("%w: this password has appeared in a data breach, please choose another", ErrWeakPassword)
+	}
+
+	return nil
+}
+
+func (p *PasswordPolicy) loadBreachedPasswords(path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		p.breached[strings.ToLower(line)] = struct{}{}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+
+	log.Printf("Loaded %d breached passwords from %s", len(p.breached), path)
+	return nil
+}
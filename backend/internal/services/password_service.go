@@ -0,0 +1,183 @@
+package services
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"backend/internal/config"
+	"backend/internal/mailer"
+	"backend/internal/models"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// ErrResetTokenInvalid is returned for unknown, used or expired reset tokens.
+var ErrResetTokenInvalid = errors.New("invalid or expired password reset token")
+
+// PasswordService handles password resets and changes.
+type PasswordService struct {
+	db       *sql.DB
+	config   *config.Config
+	mailer   mailer.Mailer
+	sessions *SessionService
+	policy   *PasswordPolicy
+}
+
+func NewPasswordService(db *sql.DB, cfg *config.Config, m mailer.Mailer, sessionService *SessionService, policy *PasswordPolicy) *PasswordService {
+	return &PasswordService{
+		db:       db,
+		config:   cfg,
+		mailer:   m,
+		sessions: sessionService,
+		policy:   policy,
+	}
+}
+
+// RequestPasswordReset emails a single-use reset link to the user. Unknown
+// addresses are ignored silently so the endpoint cannot be used to find out
+// which emails are registered.
+func (s *PasswordService) RequestPasswordReset(req models.ForgotPasswordRequest) error {
+	var userID int
+	err := s.db.QueryRow("SELECT id FROM users WHERE email = ?", req.Email).Scan(&userID)
+	if err == sql.ErrNoRows {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("error finding user: %w", err)
+	}
+
+	token, err := generateRandomToken(32)
+	if err != nil {
+		return err
+	}
+
+	// A new request supersedes any reset link sent earlier
+	_, err = s.db.Exec(`
+        UPDATE password_resets
+        SET used_at = NOW()
+        WHERE user_id = ? AND used_at IS NULL
+    `, userID)
+	if err != nil {
+		return fmt.Errorf("error invalidating previous reset tokens: %w", err)
+	}
+
+	_, err = s.db.Exec(`
+        INSERT INTO password_resets (user_id, token_hash, expires_at)
+        VALUES (?, ?, ?)
+    `, userID, hashToken(token), time.Now().Add(s.config.Auth.PasswordResetTTL))
+	if err != nil {
+		return fmt.Errorf("error creating reset token: %w", err)
+	}
+
+	link := fmt.Sprintf("%s/forgot-password?token=%s", s.config.Server.FrontendURL, token)
+	err = s.mailer.Send(mailer.Message{
+		To:      req.Email,
+		Subject: "Reset your NovelSync password",
+		Body: fmt.Sprintf("Someone requested a password reset for your NovelSync account.\n\n"+
+			"Open the link below to choose a new password. It expires in %s.\n\n%s\n\n"+
+			"If you did not request this, you can ignore this email.\n",
+			s.config.Auth.PasswordResetTTL, link),
+	})
+	if err != nil {
+		// Do not reveal delivery problems to the caller
+		log.Printf("Failed to send password reset email to user %d: %v", userID, err)
+	}
+
+	return nil
+}
+
+// ResetPassword sets a new password using a reset token and signs the user
+// out of every session.
+func (s *PasswordService) ResetPassword(req models.ResetPasswordRequest) error {
+	if err := s.policy.Validate(req.NewPassword); err != nil {
+		return err
+	}
+
+	var (
+		resetID, userID int
+		expiresAt       time.Time
+		usedAt          sql.NullTime
+	)
+	err := s.db.QueryRow(`
+        SELECT id, user_id, expires_at, used_at
+        FROM password_resets
+        WHERE token_hash = ?
+    `, hashToken(req.Token)).Scan(&resetID, &userID, &expiresAt, &usedAt)
+
+	if err == sql.ErrNoRows {
+		return ErrResetTokenInvalid
+	}
+	if err != nil {
+		return fmt.Errorf("error finding reset token: %w", err)
+	}
+	if usedAt.Valid || time.Now().After(expiresAt) {
+		return ErrResetTokenInvalid
+	}
+
+	// Consume the token first so it cannot be used twice concurrently
+	result, err := s.db.Exec(`
+        UPDATE password_resets
+        SET used_at = NOW()
+        WHERE id = ? AND used_at IS NULL
+    `, resetID)
+	if err != nil {
+		return fmt.Errorf("error consuming reset token: %w", err)
+	}
+	if affected, err := result.RowsAffected(); err == nil && affected == 0 {
+		return ErrResetTokenInvalid
+	}
+
+	if err := s.setPassword(userID, req.NewPassword); err != nil {
+		return err
+	}
+
+	return s.sessions.RevokeAllSessions(userID)
+}
+
+// ChangePassword replaces the password after checking the current one and
+// signs the user out of every session except the one making the request.
+func (s *PasswordService) ChangePassword(userID, sessionID int, req models.ChangePasswordRequest) error {
+	var passwordHash string
+	err := s.db.QueryRow("SELECT password_hash FROM users WHERE id = ?", userID).Scan(&passwordHash)
+	if err == sql.ErrNoRows {
+		return errors.New("user not found")
+	}
+	if err != nil {
+		return fmt.Errorf("error finding user: %w", err)
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(passwordHash), []byte(req.CurrentPassword)); err != nil {
+		return ErrInvalidPassword
+	}
+
+	if err := s.policy.Validate(req.NewPassword); err != nil {
+		return err
+	}
+
+	if err := s.setPassword(userID, req.NewPassword); err != nil {
+		return err
+	}
+
+	return s.sessions.RevokeOtherSessions(userID, sessionID)
+}
+
+func (s *PasswordService) setPassword(userID int, password string) error {
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return fmt.Errorf("error hashing password: %w", err)
+	}
+
+	_, err = s.db.Exec(`
+        UPDATE users
+        SET password_hash = ?, updated_at = NOW()
+        WHERE id = ?
+    `, string(hashedPassword), userID)
+	if err != nil {
+		return fmt.Errorf("error updating password: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,165 @@
+package services
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"backend/internal/models"
+)
+
+// PersonalTokenPrefix marks personal access tokens so they can be told apart
+// from JWTs in the Authorization header.
+const PersonalTokenPrefix = "nsp_"
+
+var (
+	// ErrPersonalTokenInvalid is returned for unknown, expired or revoked tokens.
+	ErrPersonalTokenInvalid = errors.New("invalid, expired or revoked personal access token")
+	// ErrPersonalTokenNotFound is returned when revoking a token the user does not own.
+	ErrPersonalTokenNotFound = errors.New("personal access token not found")
+)
+
+// PersonalTokenService manages scoped personal access tokens used by scripts.
+type PersonalTokenService struct {
+	db *sql.DB
+}
+
+func NewPersonalTokenService(db *sql.DB) *PersonalTokenService {
+	return &PersonalTokenService{db: db}
+}
+
+// CreateToken issues a new token. The plain token is only returned here.
+func (s *PersonalTokenService) CreateToken(userID int, req models.CreatePersonalAccessTokenRequest) (*models.CreatePersonalAccessTokenResponse, error) {
+	secret, err := generateRandomToken(20)
+	if err != nil {
+		return nil, err
+	}
+	token := PersonalTokenPrefix + secret
+	prefix := token[:len(PersonalTokenPrefix)+6]
+
+	var expiresAt *time.Time
+	if req.ExpiresInDays != nil {
+		expiry := time.Now().AddDate(0, 0, *req.ExpiresInDays)
+		expiresAt = &expiry
+	}
+
+	result, err := s.db.Exec(`
+        INSERT INTO personal_access_tokens (user_id, name, token_hash, token_prefix, scopes, expires_at)
+        VALUES (?, ?, ?, ?, ?, ?)
+    `, userID, req.Name, hashToken(token), prefix, strings.Join(req.Scopes, ","), expiresAt)
+	if err != nil {
+		return nil, fmt.Errorf("error creating personal access token: %w", err)
+	}
+
+	tokenID, err := result.LastInsertId()
+	if err != nil {
+		return nil, fmt.Errorf("error getting token ID: %w", err)
+	}
+
+	return &models.CreatePersonalAccessTokenResponse{
+		PersonalAccessToken: models.PersonalAccessToken{
+			ID:        int(tokenID),
+			Name:      req.Name,
+			Prefix:    prefix,
+			Scopes:    req.Scopes,
+			ExpiresAt: expiresAt,
+			CreatedAt: time.Now(),
+		},
+		Token: token,
+	}, nil
+}
+
+// ListTokens returns the user's tokens that have not been revoked.
+func (s *PersonalTokenService) ListTokens(userID int) ([]models.PersonalAccessToken, error) {
+	rows, err := s.db.Query(`
+        SELECT id, name, token_prefix, scopes, expires_at, last_used_at, created_at
+        FROM personal_access_tokens
+        WHERE user_id = ? AND revoked_at IS NULL
+        ORDER BY created_at DESC
+    `, userID)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching personal access tokens: %w", err)
+	}
+	defer rows.Close()
+
+	tokens := []models.PersonalAccessToken{}
+	for rows.Next() {
+		var (
+			token  models.PersonalAccessToken
+			scopes string
+		)
+		err := rows.Scan(
+			&token.ID,
+			&token.Name,
+			&token.Prefix,
+			&scopes,
+			&token.ExpiresAt,
+			&token.LastUsedAt,
+			&token.CreatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning personal access token: %w", err)
+		}
+		token.Scopes = splitScopes(scopes)
+		tokens = append(tokens, token)
+	}
+
+	return tokens, nil
+}
+
+// RevokeToken revokes one of the user's tokens.
+func (s *PersonalTokenService) RevokeToken(userID, tokenID int) error {
+	result, err := s.db.Exec(`
+        UPDATE personal_access_tokens
+        SET revoked_at = NOW()
+        WHERE id = ? AND user_id = ? AND revoked_at IS NULL
+    `, tokenID, userID)
+	if err != nil {
+		return fmt.Errorf("error revoking personal access token: %w", err)
+	}
+	if affected, err := result.RowsAffected(); err == nil && affected == 0 {
+		return ErrPersonalTokenNotFound
+	}
+
+	return nil
+}
+
+// ValidateToken returns the owner and scopes of an active token and records
+// when it was last used. Tokens of suspended users are rejected.
+func (s *PersonalTokenService) ValidateToken(token string) (int, []string, error) {
+	var (
+		tokenID, userID int
+		scopes          string
+	)
+	err := s.db.QueryRow(`
+        SELECT t.id, t.user_id, t.scopes
+        FROM personal_access_tokens t
+        JOIN users u ON t.user_id = u.id
+        WHERE t.token_hash = ? AND t.revoked_at IS NULL
+          AND (t.expires_at IS NULL OR t.expires_at > NOW())
+          AND u.suspended_at IS NULL
+    `, hashToken(token)).Scan(&tokenID, &userID, &scopes)
+
+	if err == sql.ErrNoRows {
+		return 0, nil, ErrPersonalTokenInvalid
+	}
+	if err != nil {
+		return 0, nil, fmt.Errorf("error validating personal access token: %w", err)
+	}
+
+	_, err = s.db.Exec("UPDATE personal_access_tokens SET last_used_at = NOW() WHERE id = ?", tokenID)
+	if err != nil {
+		return 0, nil, fmt.Errorf("error updating token usage: %w", err)
+	}
+
+	return userID, splitScopes(scopes), nil
+}
+
+func splitScopes(scopes string) []string {
+	if scopes == "" {
+		return []string{}
+	}
+	return strings.Split(scopes, ",")
+}
@@ -0,0 +1,210 @@
+package services
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+
+	"backend/internal/models"
+)
+
+var (
+	// ErrAlreadyMember is returned when inviting someone who already has access.
+	ErrAlreadyMember = errors.New("user is already a member of this project")
+	// ErrMemberNotFound is returned for users who are not members of the project.
+	ErrMemberNotFound = errors.New("member not found")
+	// ErrProjectCreator is returned when trying to change or remove the
+	// creator of a project, who always stays an owner.
+	ErrProjectCreator = errors.New("the project creator cannot be changed or removed")
+)
+
+// ProjectMemberService manages who a project is shared with.
+type ProjectMemberService struct {
+	db       *sql.DB
+	projects *ProjectService
+}
+
+func NewProjectMemberService(db *sql.DB, projectService *ProjectService) *ProjectMemberService {
+	return &ProjectMemberService{
+		db:       db,
+		projects: projectService,
+	}
+}
+
+// ListMembers returns the creator and everyone the project is shared with.
+// Any member can see the list.
+func (s *ProjectMemberService) ListMembers(projectID, userID int) ([]models.ProjectMember, error) {
+	if _, err := s.projects.ProjectRole(projectID, userID); err != nil {
+		return nil, err
+	}
+
+	rows, err := s.db.Query(`
+        SELECT u.id, u.username, u.user_name, u.profile_image, 'owner' AS role, TRUE AS creator, p.created_at AS added_at
+        FROM projects p
+        JOIN users u ON p.user_id = u.id
+        WHERE p.id = ?
+        UNION ALL
+        SELECT u.id, u.username, u.user_name, u.profile_image, m.role, FALSE, m.created_at
+        FROM project_members m
+        JOIN users u ON m.user_id = u.id
+        WHERE m.project_id = ?
+        ORDER BY creator DESC, added_at
+    `, projectID, projectID)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching members: %w", err)
+	}
+	defer rows.Close()
+
+	members := []models.ProjectMember{}
+	for rows.Next() {
+		var member models.ProjectMember
+		err := rows.Scan(
+			&member.UserID,
+			&member.Username,
+			&member.UserName,
+			&member.ProfileImage,
+			&member.Role,
+			&member.Creator,
+			&member.AddedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning member: %w", err)
+		}
+		members = append(members, member)
+	}
+
+	return members, nil
+}
+
+// AddMember shares a project with an existing user, found by email address
+// or username. Only owners can add members.
+func (s *ProjectMemberService) AddMember(projectID, actorID int, req models.AddMemberRequest) (*models.ProjectMember, error) {
+	if _, err := s.projects.requireProjectRole(projectID, actorID, models.ProjectRoleOwner); err != nil {
+		return nil, err
+	}
+
+	identifier := strings.TrimSpace(req.User)
+	var memberID int
+	err := s.db.QueryRow(`
+        SELECT id FROM users WHERE email = ? OR username = ?
+    `, normalizeEmail(identifier), strings.ToLower(identifier)).Scan(&memberID)
+	if err == sql.ErrNoRows {
+		return nil, ErrUserNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error finding user: %w", err)
+	}
+
+	_, err = s.projects.ProjectRole(projectID, memberID)
+	if err == nil {
+		return nil, ErrAlreadyMember
+	}
+	if !errors.Is(err, ErrProjectNotFound) {
+		return nil, err
+	}
+
+	_, err = s.db.Exec(`
+        INSERT INTO project_members (project_id, user_id, role, invited_by)
+        VALUES (?, ?, ?, ?)
+    `, projectID, memberID, req.Role, actorID)
+	if err != nil {
+		return nil, fmt.Errorf("error adding member: %w", err)
+	}
+
+	log.Printf("[PROJECT] user %d added user %d to project %d as %s", actorID, memberID, projectID, req.Role)
+
+	return s.getMember(projectID, memberID)
+}
+
+// UpdateMemberRole changes a member's role. Only owners can change roles.
+func (s *ProjectMemberService) UpdateMemberRole(projectID, actorID, memberID int, role string) (*models.ProjectMember, error) {
+	if err := s.checkCanManage(projectID, actorID, memberID); err != nil {
+		return nil, err
+	}
+
+	result, err := s.db.Exec(`
+        UPDATE project_members SET role = ? WHERE project_id = ? AND user_id = ?
+    `, role, projectID, memberID)
+	if err != nil {
+		return nil, fmt.Errorf("error updating member: %w", err)
+	}
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		// Also zero when the role did not change, so check it still exists
+		if _, err := s.getMember(projectID, memberID); err != nil {
+			return nil, err
+		}
+	}
+
+	log.Printf("[PROJECT] user %d changed the role of user %d on project %d to %s", actorID, memberID, projectID, role)
+
+	return s.getMember(projectID, memberID)
+}
+
+// RemoveMember revokes a member's access. Owners can remove anyone but the
+// creator, and every member can remove themselves.
+func (s *ProjectMemberService) RemoveMember(projectID, actorID, memberID int) error {
+	if actorID != memberID {
+		if err := s.checkCanManage(projectID, actorID, memberID); err != nil {
+			return err
+		}
+	} else if s.isCreator(projectID, actorID) {
+		return ErrProjectCreator
+	}
+
+	result, err := s.db.Exec("DELETE FROM project_members WHERE project_id = ? AND user_id = ?", projectID, memberID)
+	if err != nil {
+		return fmt.Errorf("error removing member: %w", err)
+	}
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		return ErrMemberNotFound
+	}
+
+	log.Printf("[PROJECT] user %d removed user %d from project %d", actorID, memberID, projectID)
+
+	return nil
+}
+
+// checkCanManage verifies the actor owns the project and the target is not
+// its creator.
+func (s *ProjectMemberService) checkCanManage(projectID, actorID, memberID int) error {
+	if _, err := s.projects.requireProjectRole(projectID, actorID, models.ProjectRoleOwner); err != nil {
+		return err
+	}
+	if s.isCreator(projectID, memberID) {
+		return ErrProjectCreator
+	}
+	return nil
+}
+
+func (s *ProjectMemberService) isCreator(projectID, userID int) bool {
+	var exists bool
+	err := s.db.QueryRow("SELECT EXISTS(SELECT 1 FROM projects WHERE id = ? AND user_id = ?)", projectID, userID).Scan(&exists)
+	return err == nil && exists
+}
+
+func (s *ProjectMemberService) getMember(projectID, memberID int) (*models.ProjectMember, error) {
+	var member models.ProjectMember
+	err := s.db.QueryRow(`
+        SELECT u.id, u.username, u.user_name, u.profile_image, m.role, m.created_at
+        FROM project_members m
+        JOIN users u ON m.user_id = u.id
+        WHERE m.project_id = ? AND m.user_id = ?
+    `, projectID, memberID).Scan(
+		&member.UserID,
+		&member.Username,
+		&member.UserName,
+		&member.ProfileImage,
+		&member.Role,
+		&member.AddedAt,
+	)
+	if err == sql.ErrNoRows {
+		return nil, ErrMemberNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error fetching member: %w", err)
+	}
+
+	return &member, nil
+}
@@ -0,0 +1,201 @@
+package services
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"backend/internal/models"
+)
+
+// ErrInvalidCursor is returned for pagination cursors that cannot be decoded
+// or that were issued for a different sort order.
+var ErrInvalidCursor = errors.New("invalid pagination cursor")
+
+const defaultProjectPageSize = 50
+
+// forkCountColumn counts the live forks of p
+const forkCountColumn = "(SELECT COUNT(*) FROM projects f WHERE f.forked_from = p.id AND f.deleted_at IS NULL)"
+
+// projectSortColumns maps each sort option to the expression it orders by.
+// The featured score is not listed: it depends on the time of the first page
+// and is ordered by featuredScoreColumn instead.
+var projectSortColumns = map[string]string{
+	models.ProjectSortUpdated:    "p.updated_at",
+	models.ProjectSortCreated:    "p.created_at",
+	models.ProjectSortTitle:      "p.title",
+	models.ProjectSortPopularity: starCountColumn,
+}
+
+// projectCursor marks where a page ended: the sort value and ID of its last
+// project, along with the sort it was issued for. Featured pages are ranked
+// as of At and continue at Offset instead, since scores decay over time.
+type projectCursor struct {
+	Sort   string     `json:"s"`
+	Order  string     `json:"o"`
+	Value  string     `json:"v,omitempty"`
+	ID     int        `json:"id,omitempty"`
+	At     *time.Time `json:"at,omitempty"`
+	Offset int        `json:"n,omitempty"`
+}
+
+// projectListing is a resolved ProjectFilter: its sort, direction and page
+// size, and the cursor to continue from, if any.
+type projectListing struct {
+	sort   string
+	order  string
+	limit  int
+	cursor *projectCursor
+	// at and offset position featured pages
+	at     time.Time
+	offset int
+}
+
+func newProjectListing(filter models.ProjectFilter) (*projectListing, error) {
+	listing := &projectListing{sort: filter.Sort, order: filter.Order, limit: filter.Limit, at: time.Now()}
+	if listing.sort == "" {
+		listing.sort = models.ProjectSortUpdated
+	}
+	if listing.order == "" {
+		listing.order = "desc"
+		if listing.sort == models.ProjectSortTitle {
+			listing.order = "asc"
+		}
+	}
+	if listing.limit <= 0 {
+		listing.limit = defaultProjectPageSize
+	}
+
+	if filter.Cursor != "" {
+		raw, err := base64.RawURLEncoding.DecodeString(filter.Cursor)
+		if err != nil {
+			return nil, ErrInvalidCursor
+		}
+		var cursor projectCursor
+		if err := json.Unmarshal(raw, &cursor); err != nil {
+			return nil, ErrInvalidCursor
+		}
+		if cursor.Sort != listing.sort || cursor.Order != listing.order {
+			return nil, ErrInvalidCursor
+		}
+		if listing.sort == models.ProjectSortFeatured {
+			if cursor.At == nil || cursor.Offset < 0 {
+				return nil, ErrInvalidCursor
+			}
+			listing.at = *cursor.At
+			listing.offset = cursor.Offset
+		}
+		listing.cursor = &cursor
+	}
+
+	return listing, nil
+}
+
+// condition returns the WHERE conditions for the date ranges of the filter
+// and for continuing after the cursor.
+func (l *projectListing) condition(filter models.ProjectFilter) (string, []interface{}, error) {
+	condition := ""
+	args := []interface{}{}
+	if !filter.CreatedFrom.IsZero() {
+		condition += " AND p.created_at >= ?"
+		args = append(args, filter.CreatedFrom)
+	}
+	if !filter.CreatedTo.IsZero() {
+		condition += " AND p.created_at < ?"
+		args = append(args, filter.CreatedTo.AddDate(0, 0, 1))
+	}
+	if !filter.UpdatedFrom.IsZero() {
+		condition += " AND p.updated_at >= ?"
+		args = append(args, filter.UpdatedFrom)
+	}
+	if !filter.UpdatedTo.IsZero() {
+		condition += " AND p.updated_at < ?"
+		args = append(args, filter.UpdatedTo.AddDate(0, 0, 1))
+	}
+
+	if l.cursor == nil || l.sort == models.ProjectSortFeatured {
+		return condition, args, nil
+	}
+
+	var value interface{}
+	switch l.sort {
+	case models.ProjectSortUpdated, models.ProjectSortCreated:
+		t, err := time.Parse(time.RFC3339Nano, l.cursor.Value)
+		if err != nil {
+			return "", nil, ErrInvalidCursor
+		}
+		value = t
+	case models.ProjectSortPopularity:
+		n, err := strconv.Atoi(l.cursor.Value)
+		if err != nil {
+			return "", nil, ErrInvalidCursor
+		}
+		value = n
+	default:
+		value = l.cursor.Value
+	}
+
+	column := projectSortColumns[l.sort]
+	operator := "<"
+	if l.order == "asc" {
+		operator = ">"
+	}
+	condition += fmt.Sprintf(" AND (%s %s ? OR (%s = ? AND p.id %s ?))", column, operator, column, operator)
+	args = append(args, value, value, l.cursor.ID)
+	return condition, args, nil
+}
+
+// orderBy returns the ORDER BY and LIMIT clauses. One extra row is fetched
+// to tell whether there is a next page.
+func (l *projectListing) orderBy() (string, []interface{}) {
+	direction := "DESC"
+	if l.order == "asc" {
+		direction = "ASC"
+	}
+	if l.sort == models.ProjectSortFeatured {
+		return fmt.Sprintf(" ORDER BY %s %s, p.id %s LIMIT ? OFFSET ?", featuredScoreColumn, direction, direction),
+			[]interface{}{l.at, l.at, l.limit + 1, l.offset}
+	}
+	column := projectSortColumns[l.sort]
+	return fmt.Sprintf(" ORDER BY %s %s, p.id %s LIMIT ?", column, direction, direction), []interface{}{l.limit + 1}
+}
+
+// page trims the extra row fetched by orderBy and builds the cursor for the
+// next page.
+func (l *projectListing) page(projects []models.ProjectListItem) *models.ProjectPage {
+	page := &models.ProjectPage{Projects: projects}
+	if len(projects) <= l.limit {
+		return page
+	}
+
+	page.Projects = projects[:l.limit]
+	page.HasMore = true
+
+	if l.sort == models.ProjectSortFeatured {
+		page.NextCursor = encodeCursor(projectCursor{Sort: l.sort, Order: l.order, At: &l.at, Offset: l.offset + l.limit})
+		return page
+	}
+
+	last := page.Projects[l.limit-1]
+	cursor := projectCursor{Sort: l.sort, Order: l.order, ID: last.ID}
+	switch l.sort {
+	case models.ProjectSortUpdated:
+		cursor.Value = last.UpdatedAt.Format(time.RFC3339Nano)
+	case models.ProjectSortCreated:
+		cursor.Value = last.CreatedAt.Format(time.RFC3339Nano)
+	case models.ProjectSortPopularity:
+		cursor.Value = strconv.Itoa(last.StarCount)
+	default:
+		cursor.Value = last.Title
+	}
+	page.NextCursor = encodeCursor(cursor)
+	return page
+}
+
+func encodeCursor(cursor projectCursor) string {
+	raw, _ := json.Marshal(cursor)
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
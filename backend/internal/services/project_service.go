@@ -1,37 +1,96 @@
 package services
 
 import (
+	"crypto/hmac"
+	"crypto/sha256"
 	"database/sql"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
+	"strconv"
 	"strings"
 	"time"
 
+	"backend/internal/config"
 	"backend/internal/models"
+	"backend/pkg/utils"
 )
 
+var (
+	// ErrProjectNotFound is returned for projects that do not exist or that
+	// the user may not access.
+	ErrProjectNotFound = errors.New("project not found or access denied")
+	// ErrProjectForbidden is returned when a member's role does not allow
+	// the requested change.
+	ErrProjectForbidden = errors.New("your role on this project does not allow this")
+	// ErrEmailNotVerified is returned when an author with an unverified email
+	// tries to publish while REQUIRE_VERIFIED_EMAIL_TO_PUBLISH is on.
+	ErrEmailNotVerified = errors.New("verify your email address before publishing projects")
+)
+
+// projectListColumns are the columns read by scanProjectList. The project
+// table must be aliased p and the author table u.
+const projectListColumns = "p.id, p.title, p.description, p.cover_image, p.visibility, p.genre, p.created_at, p.updated_at, u.user_name, u.username, " + starCountColumn + ", " + forkCountColumn + ", p.picked_at IS NOT NULL"
+
 type ProjectService struct {
-	db *sql.DB
+	db     *sql.DB
+	config *config.Config
+}
+
+func NewProjectService(db *sql.DB, cfg *config.Config) *ProjectService {
+	return &ProjectService{db: db, config: cfg}
 }
 
-func NewProjectService(db *sql.DB) *ProjectService {
-	return &ProjectService{db: db}
+// publishedFilter returns the extra WHERE condition a project must satisfy to
+// be shown to users other than its owner: not in the trash, not taken down by
+// a moderator and not written by a suspended author. The project table must
+// be aliased p and the author table u.
+func (s *ProjectService) publishedFilter() string {
+	filter := " AND p.deleted_at IS NULL AND p.unpublished_at IS NULL AND u.suspended_at IS NULL"
+	if s.config.Auth.RequireVerifiedEmailToPublish {
+		filter += " AND u.email_verified_at IS NOT NULL"
+	}
+	return filter
+}
+
+// listedFilter is publishedFilter restricted to public projects, for lists
+// such as the homepage and author profiles. Unlisted projects are only
+// reachable through a direct link.
+func (s *ProjectService) listedFilter() string {
+	return " AND p.visibility = 'public'" + s.publishedFilter()
 }
 
+// GetPublicProjectByID returns a public or unlisted project, or nil.
 func (s *ProjectService) GetPublicProjectByID(projectID int) *models.Project {
+	return s.getPublishedProject(projectID, " AND p.visibility <> 'private'")
+}
+
+// getSharedProject returns a project opened through a share link, whatever
+// its visibility, or nil if it was taken down.
+func (s *ProjectService) getSharedProject(projectID int) *models.Project {
+	return s.getPublishedProject(projectID, "")
+}
+
+func (s *ProjectService) getPublishedProject(projectID int, condition string) *models.Project {
 	var project models.Project
 	err := s.db.QueryRow(`
-        SELECT id, user_id, title, description, cover_image, project_data, created_at, updated_at
-        FROM projects 
-        WHERE id = ?
-    `, projectID).Scan(
+        SELECT p.id, p.user_id, p.title, p.description, p.cover_image, p.project_data, p.visibility, p.genre, p.forked_from,
+               `+starCountColumn+`, p.created_at, p.updated_at
+        FROM projects p
+        JOIN users u ON p.user_id = u.id
+        WHERE p.id = ?`+condition+s.publishedFilter(), projectID).Scan(
 		&project.ID,
 		&project.UserID,
 		&project.Title,
 		&project.Description,
 		&project.CoverImage,
 		&project.ProjectData,
+		&project.Visibility,
+		&project.Genre,
+		&project.ForkedFromID,
+		&project.StarCount,
 		&project.CreatedAt,
 		&project.UpdatedAt,
 	)
@@ -40,13 +99,25 @@ func (s *ProjectService) GetPublicProjectByID(projectID int) *models.Project {
 		return nil
 	}
 
+	project.ForkedFrom = s.forkSource(project.ForkedFromID)
+	if tags, err := projectTags(s.db, []int{project.ID}); err == nil {
+		project.Tags = tags[project.ID]
+	}
 	return &project
 }
 
+// CreateProject creates a project from the given diagram, from a template or
+// empty, in that order of preference.
 func (s *ProjectService) CreateProject(userID int, req models.CreateProjectRequest) (*models.Project, error) {
 	var projectData json.RawMessage
 	if req.ProjectData != nil {
 		projectData = req.ProjectData
+	} else if req.TemplateID != "" {
+		var err error
+		projectData, err = templateProjectData(s.db, req.TemplateID, userID)
+		if err != nil {
+			return nil, err
+		}
 	} else {
 		defaultData := models.ProjectData{
 			Elements: []models.Element{},
@@ -63,10 +134,18 @@ func (s *ProjectService) CreateProject(userID int, req models.CreateProjectReque
 		}
 	}
 
+	visibility := req.Visibility
+	if visibility == "" {
+		visibility = models.VisibilityPrivate
+	}
+	if err := s.checkCanPublish(userID, visibility); err != nil {
+		return nil, err
+	}
+
 	result, err := s.db.Exec(`
-        INSERT INTO projects (user_id, title, description, project_data) 
-        VALUES (?, ?, ?, ?)
-    `, userID, req.Title, req.Description, projectData)
+        INSERT INTO projects (user_id, title, description, project_data, visibility, genre) 
+        VALUES (?, ?, ?, ?, ?, ?)
+    `, userID, req.Title, req.Description, projectData, visibility, nullableString(req.Genre))
 
 	if err != nil {
 		return nil, fmt.Errorf("error creating project: %w", err)
@@ -80,56 +159,174 @@ func (s *ProjectService) CreateProject(userID int, req models.CreateProjectReque
 	return s.GetProjectByID(int(projectID), userID)
 }
 
-func (s *ProjectService) GetProjectsByUser(userID int) ([]models.ProjectListItem, error) {
+// GetProjectsByUser returns a page of the user's own projects and those
+// shared with them, with the folder each is filed in.
+func (s *ProjectService) GetProjectsByUser(userID int, filter models.ProjectFilter) (*models.ProjectPage, error) {
+	listing, err := newProjectListing(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	condition, args := discoveryFilter(filter)
+	args = append([]interface{}{userID, userID}, args...)
+	switch filter.Folder {
+	case "":
+	case "none":
+		condition += " AND p.id NOT IN (SELECT project_id FROM folder_projects WHERE user_id = ?)"
+		args = append(args, userID)
+	default:
+		folderID, err := strconv.Atoi(filter.Folder)
+		if err != nil {
+			return nil, ErrFolderNotFound
+		}
+		if err := checkFolderOwner(s.db, folderID, userID); err != nil {
+			return nil, err
+		}
+		condition += " AND p.id IN (SELECT project_id FROM folder_projects WHERE user_id = ? AND folder_id = ?)"
+		args = append(args, userID, folderID)
+	}
+
+	pageCondition, pageArgs, err := listing.condition(filter)
+	if err != nil {
+		return nil, err
+	}
+	orderBy, orderArgs := listing.orderBy()
+	args = append(append(args, pageArgs...), orderArgs...)
+
 	rows, err := s.db.Query(`
-        SELECT p.id, p.title, p.description, p.cover_image, p.created_at, p.updated_at, u.user_name
+        SELECT `+projectListColumns+`
         FROM projects p
         JOIN users u ON p.user_id = u.id
-        WHERE p.user_id = ?
-        ORDER BY p.updated_at DESC
-    `, userID)
+        WHERE p.deleted_at IS NULL
+          AND (p.user_id = ? OR p.id IN (SELECT project_id FROM project_members WHERE user_id = ?))`+condition+pageCondition+orderBy, args...)
 
 	if err != nil {
 		return nil, fmt.Errorf("error fetching projects: %w", err)
 	}
-	defer rows.Close()
 
-	var projects []models.ProjectListItem
-	for rows.Next() {
-		var project models.ProjectListItem
-		err := rows.Scan(
-			&project.ID,
-			&project.Title,
-			&project.Description,
-			&project.CoverImage,
-			&project.CreatedAt,
-			&project.UpdatedAt,
-			&project.AuthorName,
-		)
-		if err != nil {
-			return nil, fmt.Errorf("error scanning project: %w", err)
+	projects, err := s.scanProjectList(rows)
+	if err != nil {
+		return nil, err
+	}
+
+	folders, err := projectFolders(s.db, userID)
+	if err != nil {
+		return nil, err
+	}
+	for i := range projects {
+		if folderID, ok := folders[projects[i].ID]; ok {
+			projects[i].FolderID = &folderID
 		}
-		projects = append(projects, project)
 	}
 
-	return projects, nil
+	return listing.page(projects), nil
+}
+
+// RecordView counts a view of a project for the featured ranking. Each viewer,
+// the signed-in user or else the client IP, counts at most once per project
+// per day. Views are kept per day; failures are only logged since they must
+// not break reading.
+func (s *ProjectService) RecordView(projectID, userID int, clientIP string) {
+	visitor := "ip:" + clientIP
+	if userID != 0 {
+		visitor = fmt.Sprintf("user:%d", userID)
+	}
+	mac := hmac.New(sha256.New, []byte(s.config.JWT.Secret))
+	mac.Write([]byte(visitor))
+
+	// Affects no rows when the visitor was already counted today
+	result, err := s.db.Exec(`
+        INSERT INTO project_view_visitors (project_id, visitor, day) VALUES (?, ?, CURDATE())
+        ON DUPLICATE KEY UPDATE day = VALUES(day)
+    `, projectID, hex.EncodeToString(mac.Sum(nil)))
+	if err != nil {
+		log.Printf("failed to record visitor of project %d: %v", projectID, err)
+		return
+	}
+	if affected, err := result.RowsAffected(); err == nil && affected == 0 {
+		return
+	}
+
+	_, err = s.db.Exec(`
+        INSERT INTO project_views (project_id, day, views) VALUES (?, CURDATE(), 1)
+        ON DUPLICATE KEY UPDATE views = views + 1
+    `, projectID)
+	if err != nil {
+		log.Printf("failed to record view of project %d: %v", projectID, err)
+	}
 }
 
-func (s *ProjectService) GetAllProjects() ([]models.ProjectListItem, error) {
+// GetAllProjects returns a page of public projects, optionally only those
+// with a tag or genre.
+func (s *ProjectService) GetAllProjects(filter models.ProjectFilter) (*models.ProjectPage, error) {
+	listing, err := newProjectListing(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	condition, args := discoveryFilter(filter)
+	pageCondition, pageArgs, err := listing.condition(filter)
+	if err != nil {
+		return nil, err
+	}
+	orderBy, orderArgs := listing.orderBy()
+	args = append(append(args, pageArgs...), orderArgs...)
+
 	rows, err := s.db.Query(`
-        SELECT p.id, p.title, p.description, p.cover_image, p.created_at, p.updated_at, u.user_name
+        SELECT `+projectListColumns+`
         FROM projects p
         JOIN users u ON p.user_id = u.id
-        ORDER BY p.updated_at DESC
-        LIMIT 50
-    `)
+        WHERE 1 = 1`+s.listedFilter()+condition+pageCondition+orderBy, args...)
 
 	if err != nil {
 		return nil, fmt.Errorf("error fetching all projects: %w", err)
 	}
+
+	projects, err := s.scanProjectList(rows)
+	if err != nil {
+		return nil, err
+	}
+	return listing.page(projects), nil
+}
+
+// GetPublicProjectsByUser returns the projects shown on an author's public profile.
+func (s *ProjectService) GetPublicProjectsByUser(userID int) ([]models.ProjectListItem, error) {
+	rows, err := s.db.Query(`
+        SELECT `+projectListColumns+`
+        FROM projects p
+        JOIN users u ON p.user_id = u.id
+        WHERE p.user_id = ?`+s.listedFilter()+`
+        ORDER BY p.updated_at DESC
+    `, userID)
+
+	if err != nil {
+		return nil, fmt.Errorf("error fetching projects: %w", err)
+	}
+
+	return s.scanProjectList(rows)
+}
+
+// discoveryFilter returns the WHERE conditions for the tag and genre filters.
+func discoveryFilter(filter models.ProjectFilter) (string, []interface{}) {
+	condition := ""
+	args := []interface{}{}
+	if filter.Tag != "" {
+		condition += " AND p.id IN (SELECT project_id FROM project_tags WHERE tag = ?)"
+		args = append(args, normalizeTag(filter.Tag))
+	}
+	if filter.Genre != "" {
+		condition += " AND p.genre = ?"
+		args = append(args, filter.Genre)
+	}
+	return condition, args
+}
+
+// scanProjectList reads rows selected as projectListColumns, closes them and
+// loads the tags of the projects.
+func (s *ProjectService) scanProjectList(rows *sql.Rows) ([]models.ProjectListItem, error) {
 	defer rows.Close()
 
-	var projects []models.ProjectListItem
+	projects := []models.ProjectListItem{}
 	for rows.Next() {
 		var project models.ProjectListItem
 		err := rows.Scan(
@@ -137,51 +334,153 @@ func (s *ProjectService) GetAllProjects() ([]models.ProjectListItem, error) {
 			&project.Title,
 			&project.Description,
 			&project.CoverImage,
+			&project.Visibility,
+			&project.Genre,
 			&project.CreatedAt,
 			&project.UpdatedAt,
 			&project.AuthorName,
+			&project.AuthorUsername,
+			&project.StarCount,
+			&project.ForkCount,
+			&project.EditorPick,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("error scanning project: %w", err)
 		}
 		projects = append(projects, project)
 	}
+	rows.Close()
+
+	ids := make([]int, len(projects))
+	for i, project := range projects {
+		ids[i] = project.ID
+	}
+	tags, err := projectTags(s.db, ids)
+	if err != nil {
+		return nil, err
+	}
+	for i := range projects {
+		projects[i].Tags = tags[projects[i].ID]
+	}
 
 	return projects, nil
 }
 
-func (s *ProjectService) GetProjectByID(projectID, userID int) (*models.Project, error) {
-	var project models.Project
+// ProjectRole returns the user's role on a project: owner for its creator,
+// otherwise the role from project_members. Users without access get
+// ErrProjectNotFound so that private projects are not revealed, and so do
+// projects in the trash.
+func (s *ProjectService) ProjectRole(projectID, userID int) (string, error) {
+	return s.projectRole(projectID, userID, false)
+}
+
+func (s *ProjectService) projectRole(projectID, userID int, trashed bool) (string, error) {
+	condition := " AND p.deleted_at IS NULL"
+	if trashed {
+		condition = " AND p.deleted_at IS NOT NULL"
+	}
+
+	var (
+		ownerID    int
+		memberRole sql.NullString
+	)
 	err := s.db.QueryRow(`
-        SELECT id, user_id, title, description, cover_image, project_data, created_at, updated_at
-        FROM projects 
-        WHERE id = ? AND user_id = ?
-    `, projectID, userID).Scan(
+        SELECT p.user_id, m.role
+        FROM projects p
+        LEFT JOIN project_members m ON m.project_id = p.id AND m.user_id = ?
+        WHERE p.id = ?`+condition, userID, projectID).Scan(&ownerID, &memberRole)
+	if err == sql.ErrNoRows {
+		return "", ErrProjectNotFound
+	}
+	if err != nil {
+		return "", fmt.Errorf("error checking project access: %w", err)
+	}
+
+	if ownerID == userID {
+		return models.ProjectRoleOwner, nil
+	}
+	if memberRole.Valid {
+		return memberRole.String, nil
+	}
+	return "", ErrProjectNotFound
+}
+
+// requireProjectRole returns the user's role if it is at least minimum.
+func (s *ProjectService) requireProjectRole(projectID, userID int, minimum string) (string, error) {
+	role, err := s.ProjectRole(projectID, userID)
+	if err != nil {
+		return "", err
+	}
+	if projectRoleRank(role) < projectRoleRank(minimum) {
+		return "", ErrProjectForbidden
+	}
+	return role, nil
+}
+
+// GetProjectByID returns a project the user is a member of, with their role.
+func (s *ProjectService) GetProjectByID(projectID, userID int) (*models.Project, error) {
+	role, err := s.requireProjectRole(projectID, userID, models.ProjectRoleViewer)
+	if err != nil {
+		return nil, err
+	}
+
+	project := models.Project{Role: role}
+	err = s.db.QueryRow(`
+        SELECT p.id, p.user_id, p.title, p.description, p.cover_image, p.project_data, p.visibility, p.genre, p.forked_from,
+               `+starCountColumn+`, p.created_at, p.updated_at
+        FROM projects p
+        WHERE p.id = ?
+    `, projectID).Scan(
 		&project.ID,
 		&project.UserID,
 		&project.Title,
 		&project.Description,
 		&project.CoverImage,
 		&project.ProjectData,
+		&project.Visibility,
+		&project.Genre,
+		&project.ForkedFromID,
+		&project.StarCount,
 		&project.CreatedAt,
 		&project.UpdatedAt,
 	)
 
 	if err == sql.ErrNoRows {
-		return nil, fmt.Errorf("project not found or access denied")
+		return nil, ErrProjectNotFound
 	}
 	if err != nil {
 		return nil, fmt.Errorf("error fetching project: %w", err)
 	}
 
+	project.ForkedFrom = s.forkSource(project.ForkedFromID)
+	tags, err := projectTags(s.db, []int{project.ID})
+	if err != nil {
+		return nil, err
+	}
+	project.Tags = tags[project.ID]
+
 	return &project, nil
 }
 
+// UpdateProject changes a project's details. Editors can change the content;
+// only owners can change its visibility.
 func (s *ProjectService) UpdateProject(projectID, userID int, req models.UpdateProjectRequest) (*models.Project, error) {
-	_, err := s.GetProjectByID(projectID, userID)
+	role, err := s.requireProjectRole(projectID, userID, models.ProjectRoleEditor)
 	if err != nil {
 		return nil, err
 	}
+	if req.Visibility != nil && role != models.ProjectRoleOwner {
+		return nil, ErrProjectForbidden
+	}
+	if req.Visibility != nil {
+		var authorID int
+		if err := s.db.QueryRow("SELECT user_id FROM projects WHERE id = ?", projectID).Scan(&authorID); err != nil {
+			return nil, fmt.Errorf("error fetching project: %w", err)
+		}
+		if err := s.checkCanPublish(authorID, *req.Visibility); err != nil {
+			return nil, err
+		}
+	}
 
 	setParts := []string{}
 	args := []interface{}{}
@@ -207,15 +506,23 @@ func (s *ProjectService) UpdateProject(projectID, userID int, req models.UpdateP
 		setParts = append(setParts, "project_data = ?")
 		args = append(args, *req.ProjectData)
 	}
+	if req.Visibility != nil {
+		setParts = append(setParts, "visibility = ?")
+		args = append(args, *req.Visibility)
+	}
+	if req.Genre != nil {
+		setParts = append(setParts, "genre = ?")
+		args = append(args, nullableString(*req.Genre))
+	}
 
 	if len(setParts) == 0 {
 		return s.GetProjectByID(projectID, userID)
 	}
 
 	setParts = append(setParts, "updated_at = NOW()")
-	args = append(args, projectID, userID)
+	args = append(args, projectID)
 
-	query := fmt.Sprintf("UPDATE projects SET %s WHERE id = ? AND user_id = ?", strings.Join(setParts, ", "))
+	query := fmt.Sprintf("UPDATE projects SET %s WHERE id = ?", strings.Join(setParts, ", "))
 
 	_, err = s.db.Exec(query, args...)
 	if err != nil {
@@ -225,13 +532,88 @@ func (s *ProjectService) UpdateProject(projectID, userID int, req models.UpdateP
 	return s.GetProjectByID(projectID, userID)
 }
 
+// DeleteProject moves a project to the trash, where it can be restored until
+// it is purged. Only owners can delete.
 func (s *ProjectService) DeleteProject(projectID, userID int) error {
-	_, err := s.GetProjectByID(projectID, userID)
+	_, err := s.requireProjectRole(projectID, userID, models.ProjectRoleOwner)
+	if err != nil {
+		return err
+	}
+
+	// updated_at tracks edits of the content, so trashing must not bump it
+	_, err = s.db.Exec(`
+        UPDATE projects SET deleted_at = NOW(), updated_at = updated_at WHERE id = ? AND deleted_at IS NULL
+    `, projectID)
+	if err != nil {
+		return fmt.Errorf("error deleting project: %w", err)
+	}
+
+	return nil
+}
+
+// GetTrash returns the deleted projects the user owns, with when each will be
+// purged.
+func (s *ProjectService) GetTrash(userID int) ([]models.TrashedProject, error) {
+	rows, err := s.db.Query(`
+        SELECT p.id, p.title, p.description, p.cover_image, p.visibility, p.created_at, p.updated_at, u.user_name, u.username, p.deleted_at
+        FROM projects p
+        JOIN users u ON p.user_id = u.id
+        WHERE p.deleted_at IS NOT NULL
+          AND (p.user_id = ? OR p.id IN (SELECT project_id FROM project_members WHERE user_id = ? AND role = 'owner'))
+        ORDER BY p.deleted_at DESC
+    `, userID, userID)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching trash: %w", err)
+	}
+	defer rows.Close()
+
+	projects := []models.TrashedProject{}
+	for rows.Next() {
+		var project models.TrashedProject
+		err := rows.Scan(
+			&project.ID,
+			&project.Title,
+			&project.Description,
+			&project.CoverImage,
+			&project.Visibility,
+			&project.CreatedAt,
+			&project.UpdatedAt,
+			&project.AuthorName,
+			&project.AuthorUsername,
+			&project.DeletedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning project: %w", err)
+		}
+		project.PurgeAt = project.DeletedAt.Add(s.config.Projects.TrashRetention)
+		projects = append(projects, project)
+	}
+
+	return projects, nil
+}
+
+// RestoreProject takes a project out of the trash. Only owners can restore.
+func (s *ProjectService) RestoreProject(projectID, userID int) (*models.Project, error) {
+	if err := s.requireTrashOwner(projectID, userID); err != nil {
+		return nil, err
+	}
+
+	_, err := s.db.Exec("UPDATE projects SET deleted_at = NULL, updated_at = updated_at WHERE id = ?", projectID)
 	if err != nil {
+		return nil, fmt.Errorf("error restoring project: %w", err)
+	}
+
+	return s.GetProjectByID(projectID, userID)
+}
+
+// PurgeProject permanently deletes a project that is already in the trash,
+// together with everything that cascades from it. Only owners can purge.
+func (s *ProjectService) PurgeProject(projectID, userID int) error {
+	if err := s.requireTrashOwner(projectID, userID); err != nil {
 		return err
 	}
 
-	_, err = s.db.Exec("DELETE FROM projects WHERE id = ? AND user_id = ?", projectID, userID)
+	_, err := s.db.Exec("DELETE FROM projects WHERE id = ? AND deleted_at IS NOT NULL", projectID)
 	if err != nil {
 		return fmt.Errorf("error deleting project: %w", err)
 	}
@@ -239,12 +621,65 @@ func (s *ProjectService) DeleteProject(projectID, userID int) error {
 	return nil
 }
 
+// PurgeExpiredTrash permanently deletes projects that have been in the trash
+// longer than the retention period and returns how many were deleted.
+func (s *ProjectService) PurgeExpiredTrash() (int64, error) {
+	cutoff := time.Now().Add(-s.config.Projects.TrashRetention)
+	result, err := s.db.Exec("DELETE FROM projects WHERE deleted_at IS NOT NULL AND deleted_at < ?", cutoff)
+	if err != nil {
+		return 0, fmt.Errorf("error purging trash: %w", err)
+	}
+
+	purged, _ := result.RowsAffected()
+	return purged, nil
+}
+
+// PurgeTrashPeriodically runs PurgeExpiredTrash every purge interval. It
+// never returns and is meant to run in its own goroutine. A zero interval
+// disables the purge.
+func (s *ProjectService) PurgeTrashPeriodically() {
+	if s.config.Projects.TrashPurgeInterval <= 0 {
+		log.Println("Trash purge disabled")
+		return
+	}
+
+	ticker := time.NewTicker(s.config.Projects.TrashPurgeInterval)
+	defer ticker.Stop()
+
+	for {
+		purged, err := s.PurgeExpiredTrash()
+		if err != nil {
+			log.Printf("Trash purge failed: %v", err)
+		} else if purged > 0 {
+			log.Printf("Purged %d projects from the trash", purged)
+		}
+		<-ticker.C
+	}
+}
+
+func (s *ProjectService) requireTrashOwner(projectID, userID int) error {
+	role, err := s.projectRole(projectID, userID, true)
+	if err != nil {
+		return err
+	}
+	if role != models.ProjectRoleOwner {
+		return ErrProjectForbidden
+	}
+	return nil
+}
+
+// SaveProjectData replaces the diagram of a project the user can edit.
 func (s *ProjectService) SaveProjectData(projectID, userID int, projectData json.RawMessage) error {
-	_, err := s.db.Exec(`
+	_, err := s.requireProjectRole(projectID, userID, models.ProjectRoleEditor)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.db.Exec(`
         UPDATE projects 
         SET project_data = ?, updated_at = NOW() 
-        WHERE id = ? AND user_id = ?
-    `, projectData, projectID, userID)
+        WHERE id = ?
+    `, projectData, projectID)
 
 	if err != nil {
 		return fmt.Errorf("error saving project data: %w", err)
@@ -252,3 +687,161 @@ func (s *ProjectService) SaveProjectData(projectID, userID int, projectData json
 
 	return nil
 }
+
+// ForkProject copies a project into a new private project owned by the
+// user. Only projects copyProject allows can be forked. Element IDs are
+// regenerated in the copy.
+func (s *ProjectService) ForkProject(projectID, userID int) (*models.Project, error) {
+	source, err := s.copyProject(projectID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Forks always start private, so they need no checkCanPublish
+	projectData, err := regenerateElementIDs(source.ProjectData)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := s.db.Exec(`
+        INSERT INTO projects (user_id, title, description, cover_image, project_data, visibility, genre, forked_from) 
+        VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+    `, userID, source.Title, source.Description, source.CoverImage, projectData, models.VisibilityPrivate, source.Genre, source.ID)
+	if err != nil {
+		return nil, fmt.Errorf("error forking project: %w", err)
+	}
+
+	forkID, err := result.LastInsertId()
+	if err != nil {
+		return nil, fmt.Errorf("error getting project ID: %w", err)
+	}
+
+	return s.GetProjectByID(int(forkID), userID)
+}
+
+// copyProject returns a project the user may copy out, by forking or saving
+// it as a template. Owners can copy their own projects, and anyone can copy a
+// public project; viewers and editors of a private or unlisted project cannot.
+func (s *ProjectService) copyProject(projectID, userID int) (*models.Project, error) {
+	role, err := s.ProjectRole(projectID, userID)
+	if err != nil && !errors.Is(err, ErrProjectNotFound) {
+		return nil, err
+	}
+	if err == nil && projectRoleRank(role) >= projectRoleRank(models.ProjectRoleOwner) {
+		return s.GetProjectByID(projectID, userID)
+	}
+
+	source := s.getPublishedProject(projectID, " AND p.visibility = 'public'")
+	if source == nil {
+		// Members learn the project exists, but not others
+		if role != "" {
+			return nil, ErrProjectForbidden
+		}
+		return nil, ErrProjectNotFound
+	}
+	return source, nil
+}
+
+// checkCanPublish rejects making a project unlisted or public while its
+// author's email is unverified, if the configuration requires verification.
+func (s *ProjectService) checkCanPublish(authorID int, visibility string) error {
+	if visibility == models.VisibilityPrivate || !s.config.Auth.RequireVerifiedEmailToPublish {
+		return nil
+	}
+
+	var verified bool
+	err := s.db.QueryRow("SELECT email_verified_at IS NOT NULL FROM users WHERE id = ?", authorID).Scan(&verified)
+	if err != nil {
+		return fmt.Errorf("error checking email verification: %w", err)
+	}
+	if !verified {
+		return ErrEmailNotVerified
+	}
+	return nil
+}
+
+// forkSource returns the original of a fork if it is still public or
+// unlisted, so private originals are not revealed through their forks.
+func (s *ProjectService) forkSource(forkedFromID *int) *models.ProjectReference {
+	if forkedFromID == nil {
+		return nil
+	}
+
+	var source models.ProjectReference
+	err := s.db.QueryRow(`
+        SELECT p.id, p.title, u.user_name, u.username
+        FROM projects p
+        JOIN users u ON p.user_id = u.id
+        WHERE p.id = ? AND p.visibility <> 'private'`+s.publishedFilter(), *forkedFromID).Scan(
+		&source.ID,
+		&source.Title,
+		&source.AuthorName,
+		&source.AuthorUsername,
+	)
+	if err != nil {
+		return nil
+	}
+
+	return &source
+}
+
+// regenerateElementIDs gives every element a new ID and points the sourceId
+// and targetId of relationships at the new IDs. Fields the backend does not
+// know about are kept as they are.
+func regenerateElementIDs(projectData json.RawMessage) (json.RawMessage, error) {
+	if len(projectData) == 0 || string(projectData) == "null" {
+		return projectData, nil
+	}
+
+	var data map[string]json.RawMessage
+	if err := json.Unmarshal(projectData, &data); err != nil {
+		return nil, fmt.Errorf("invalid project_data JSON: %w", err)
+	}
+	rawElements, ok := data["elements"]
+	if !ok || string(rawElements) == "null" {
+		return projectData, nil
+	}
+
+	var elements []map[string]interface{}
+	if err := json.Unmarshal(rawElements, &elements); err != nil {
+		return nil, fmt.Errorf("invalid project_data elements: %w", err)
+	}
+
+	newIDs := make(map[string]string, len(elements))
+	for _, element := range elements {
+		if id, ok := element["id"].(string); ok && id != "" {
+			if _, seen := newIDs[id]; !seen {
+				newIDs[id] = utils.GenerateID(12)
+			}
+			element["id"] = newIDs[id]
+		}
+	}
+	for _, element := range elements {
+		for _, key := range []string{"sourceId", "targetId"} {
+			if id, ok := element[key].(string); ok {
+				if newID, found := newIDs[id]; found {
+					element[key] = newID
+				}
+			}
+		}
+	}
+
+	var err error
+	data["elements"], err = json.Marshal(elements)
+	if err != nil {
+		return nil, fmt.Errorf("error encoding project data: %w", err)
+	}
+
+	return json.Marshal(data)
+}
+
+func projectRoleRank(role string) int {
+	switch role {
+	case models.ProjectRoleOwner:
+		return 2
+	case models.ProjectRoleEditor:
+		return 1
+	default:
+		return 0
+	}
+}
@@ -0,0 +1,336 @@
+package services
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"html"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"backend/internal/models"
+)
+
+const (
+	defaultSearchLimit = 20
+	// maxSearchCandidates caps the projects scored in Go after the SQL
+	// prefilter, which keeps the best title and element name matches first.
+	maxSearchCandidates = 200
+	maxSearchTerms      = 8
+	maxMatchesPerResult = 5
+	snippetRadius       = 60
+)
+
+// Field weights used to rank search results
+var searchWeights = map[string]float64{
+	models.SearchFieldTitle:            10,
+	models.SearchFieldCharacter:        8,
+	models.SearchFieldRelationship:     4,
+	models.SearchFieldDescription:      3,
+	models.SearchFieldCharacterDetails: 2,
+}
+
+// SearchService searches project titles and descriptions, and the
+// characters and relationships inside project data.
+type SearchService struct {
+	db         *sql.DB
+	projects   *ProjectService
+	characters *CharacterService
+}
+
+func NewSearchService(db *sql.DB, projectService *ProjectService, characterService *CharacterService) *SearchService {
+	return &SearchService{
+		db:         db,
+		projects:   projectService,
+		characters: characterService,
+	}
+}
+
+// searchQuery is a parsed query: its lowercase terms, one pattern per term
+// and a pattern matching any of them for highlighting.
+type searchQuery struct {
+	phrase   string
+	terms    []*regexp.Regexp
+	anyTerm  *regexp.Regexp
+	keywords []string
+}
+
+func parseSearchQuery(q string) *searchQuery {
+	query := &searchQuery{phrase: strings.ToLower(strings.Join(strings.Fields(q), " "))}
+	seen := map[string]bool{}
+	quoted := []string{}
+	for _, word := range strings.Fields(query.phrase) {
+		if seen[word] || len(query.keywords) == maxSearchTerms {
+			continue
+		}
+		seen[word] = true
+		query.keywords = append(query.keywords, word)
+		query.terms = append(query.terms, regexp.MustCompile("(?i)"+regexp.QuoteMeta(word)))
+		quoted = append(quoted, regexp.QuoteMeta(word))
+	}
+	if len(quoted) > 0 {
+		query.anyTerm = regexp.MustCompile("(?i)" + strings.Join(quoted, "|"))
+	}
+	return query
+}
+
+// searchCandidate is a project returned by the SQL prefilter
+type searchCandidate struct {
+	item        models.ProjectListItem
+	projectData json.RawMessage
+	hasAccess   bool
+	result      models.SearchResult
+	matched     []bool
+}
+
+// Search returns the projects matching every word of q, best first. The
+// caller's own and shared projects are searched whatever their visibility;
+// other projects only when public and published. Hidden characters and
+// relationships are only searched in projects the caller has access to.
+func (s *SearchService) Search(q string, userID, limit int) ([]models.SearchResult, error) {
+	if limit <= 0 {
+		limit = defaultSearchLimit
+	}
+
+	query := parseSearchQuery(q)
+	if len(query.keywords) == 0 {
+		return []models.SearchResult{}, nil
+	}
+
+	candidates, err := s.findCandidates(query, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	results := []models.SearchResult{}
+	for _, candidate := range candidates {
+		if s.scoreCandidate(query, candidate) {
+			results = append(results, candidate.result)
+		}
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		return results[i].Score > results[j].Score
+	})
+	if len(results) > limit {
+		results = results[:limit]
+	}
+
+	ids := make([]int, len(results))
+	for i, result := range results {
+		ids[i] = result.Project.ID
+	}
+	tags, err := projectTags(s.db, ids)
+	if err != nil {
+		return nil, err
+	}
+	for i := range results {
+		results[i].Project.Tags = tags[results[i].Project.ID]
+	}
+
+	return results, nil
+}
+
+// findCandidates loads the visible projects whose title, description or
+// project data contain every keyword. The data check runs on the raw JSON,
+// so candidates are matched again field by field in scoreCandidate. Before
+// the candidate cap, projects are ranked coarsely with the search weights on
+// the title, the element names (characters and relationship labels) and the
+// description, so strong matches in older projects are not cut off.
+func (s *SearchService) findCandidates(query *searchQuery, userID int) ([]*searchCandidate, error) {
+	condition := ""
+	args := []interface{}{userID, userID, userID, userID}
+	rank := []string{}
+	rankArgs := []interface{}{}
+	for _, keyword := range query.keywords {
+		pattern := "%" + escapeLike(keyword) + "%"
+		condition += ` AND (LOWER(p.title) LIKE ? OR LOWER(p.description) LIKE ? OR LOWER(CAST(p.project_data AS CHAR)) LIKE ?)`
+		args = append(args, pattern, pattern, pattern)
+
+		rank = append(rank, fmt.Sprintf(
+			"(LOWER(p.title) LIKE ?) * %g + COALESCE(LOWER(CAST(JSON_EXTRACT(p.project_data, '$.elements[*].text') AS CHAR)) LIKE ?, 0) * %g + COALESCE(LOWER(p.description) LIKE ?, 0) * %g",
+			searchWeights[models.SearchFieldTitle], searchWeights[models.SearchFieldCharacter], searchWeights[models.SearchFieldDescription]))
+		rankArgs = append(rankArgs, pattern, pattern, pattern)
+	}
+	args = append(append(args, rankArgs...), maxSearchCandidates)
+
+	rows, err := s.db.Query(`
+        SELECT `+projectListColumns+`, p.project_data,
+               (p.user_id = ? OR EXISTS (SELECT 1 FROM project_members m WHERE m.project_id = p.id AND m.user_id = ?)) AS has_access
+        FROM projects p
+        JOIN users u ON p.user_id = u.id
+        WHERE ((p.deleted_at IS NULL AND (p.user_id = ? OR p.id IN (SELECT project_id FROM project_members WHERE user_id = ?)))
+           OR (1 = 1`+s.projects.listedFilter()+`))`+condition+`
+        ORDER BY `+strings.Join(rank, " + ")+` DESC, p.updated_at DESC
+        LIMIT ?
+    `, args...)
+	if err != nil {
+		return nil, fmt.Errorf("error searching projects: %w", err)
+	}
+	defer rows.Close()
+
+	candidates := []*searchCandidate{}
+	for rows.Next() {
+		candidate := &searchCandidate{}
+		item := &candidate.item
+		err := rows.Scan(
+			&item.ID,
+			&item.Title,
+			&item.Description,
+			&item.CoverImage,
+			&item.Visibility,
+			&item.Genre,
+			&item.CreatedAt,
+			&item.UpdatedAt,
+			&item.AuthorName,
+			&item.AuthorUsername,
+			&item.StarCount,
+			&item.ForkCount,
+			&item.EditorPick,
+			&candidate.projectData,
+			&candidate.hasAccess,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning project: %w", err)
+		}
+		candidates = append(candidates, candidate)
+	}
+
+	return candidates, rows.Err()
+}
+
+// scoreCandidate matches the query against each field of a project and
+// fills in its result. It reports whether every keyword matched somewhere.
+func (s *SearchService) scoreCandidate(query *searchQuery, candidate *searchCandidate) bool {
+	candidate.matched = make([]bool, len(query.keywords))
+	candidate.result = models.SearchResult{Project: candidate.item, Matches: []models.SearchMatch{}}
+
+	s.matchField(query, candidate, models.SearchFieldTitle, candidate.item.Title, "", "")
+	if candidate.item.Description != nil {
+		s.matchField(query, candidate, models.SearchFieldDescription, *candidate.item.Description, "", "")
+	}
+
+	characters, err := s.characters.ExtractCharactersFromProjectData(candidate.projectData)
+	if err == nil {
+		names := map[string]string{}
+		for _, character := range characters {
+			// Hidden names must not leak through relationship labels either
+			if character.Hidden && !candidate.hasAccess {
+				continue
+			}
+			names[character.ID] = character.Name
+			s.matchField(query, candidate, models.SearchFieldCharacter, character.Name, character.ID, "")
+			s.matchField(query, candidate, models.SearchFieldCharacterDetails, character.Description, character.ID, character.Name)
+		}
+
+		relationships, _ := s.characters.ExtractRelationshipsFromProjectData(candidate.projectData)
+		for _, relationship := range relationships {
+			if relationship.Hidden && !candidate.hasAccess {
+				continue
+			}
+			label := names[relationship.SourceCharacterID] + " → " + names[relationship.TargetCharacterID]
+			text := strings.TrimSpace(relationship.Description + " " + relationship.RelationshipType)
+			s.matchField(query, candidate, models.SearchFieldRelationship, text, relationship.ID, label)
+		}
+	}
+
+	for _, matched := range candidate.matched {
+		if !matched {
+			return false
+		}
+	}
+
+	matches := candidate.result.Matches
+	sort.SliceStable(matches, func(i, j int) bool {
+		return searchWeights[matches[i].Field] > searchWeights[matches[j].Field]
+	})
+	if len(matches) > maxMatchesPerResult {
+		candidate.result.Matches = matches[:maxMatchesPerResult]
+	}
+	return true
+}
+
+// matchField scores one field and records a highlighted snippet when any
+// keyword matched. Matching the whole query as a phrase doubles the score.
+func (s *SearchService) matchField(query *searchQuery, candidate *searchCandidate, field, text, elementID, label string) {
+	if text == "" {
+		return
+	}
+
+	score := 0.0
+	for i, term := range query.terms {
+		if term.MatchString(text) {
+			candidate.matched[i] = true
+			score += searchWeights[field]
+		}
+	}
+	if score == 0 {
+		return
+	}
+	if len(query.keywords) > 1 && strings.Contains(strings.ToLower(text), query.phrase) {
+		score *= 2
+	}
+	if strings.EqualFold(strings.TrimSpace(text), query.phrase) {
+		score *= 1.5
+	}
+
+	candidate.result.Score += score
+	candidate.result.Matches = append(candidate.result.Matches, models.SearchMatch{
+		Field:     field,
+		ElementID: elementID,
+		Label:     label,
+		Snippet:   highlightSnippet(text, query.anyTerm),
+	})
+}
+
+// highlightSnippet cuts text down to a window around its first match,
+// escapes it and wraps every match in <mark> tags.
+func highlightSnippet(text string, pattern *regexp.Regexp) string {
+	text = strings.Join(strings.Fields(text), " ")
+	locations := pattern.FindAllStringIndex(text, -1)
+	if len(locations) == 0 {
+		return html.EscapeString(text)
+	}
+
+	start, end := 0, len(text)
+	if first := locations[0][0]; first > snippetRadius {
+		start = first - snippetRadius
+	}
+	if start+2*snippetRadius < end {
+		end = start + 2*snippetRadius
+	}
+	for start > 0 && !utf8.RuneStart(text[start]) {
+		start++
+	}
+	for end < len(text) && !utf8.RuneStart(text[end]) {
+		end--
+	}
+
+	var snippet strings.Builder
+	if start > 0 {
+		snippet.WriteString("…")
+	}
+	position := start
+	for _, location := range locations {
+		if location[0] < position || location[1] > end {
+			continue
+		}
+		snippet.WriteString(html.EscapeString(text[position:location[0]]))
+		snippet.WriteString("<mark>")
+		snippet.WriteString(html.EscapeString(text[location[0]:location[1]]))
+		snippet.WriteString("</mark>")
+		position = location[1]
+	}
+	snippet.WriteString(html.EscapeString(text[position:end]))
+	if end < len(text) {
+		snippet.WriteString("…")
+	}
+	return snippet.String()
+}
+
+// escapeLike escapes the LIKE wildcards in s
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
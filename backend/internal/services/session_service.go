@@ -0,0 +1,276 @@
+package services
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"backend/internal/models"
+	"backend/pkg/utils"
+)
+
+var (
+	// ErrSessionInvalid is returned when a token's session is unknown, revoked or expired.
+	ErrSessionInvalid = errors.New("session is invalid or has been revoked")
+	// ErrRefreshTokenInvalid is returned for unknown or expired refresh tokens.
+	ErrRefreshTokenInvalid = errors.New("invalid or expired refresh token")
+	// ErrRefreshTokenReused is returned when an already rotated refresh token
+	// is presented again. The whole token family is revoked when this happens.
+	ErrRefreshTokenReused = errors.New("refresh token has already been used")
+	// ErrSessionNotFound is returned when signing out a session that is not
+	// one of the user's active sessions.
+	ErrSessionNotFound = errors.New("session not found")
+)
+
+// SessionService keeps track of issued tokens in the sessions table so they
+// can be revoked before they expire.
+type SessionService struct {
+	db *sql.DB
+}
+
+func NewSessionService(db *sql.DB) *SessionService {
+	return &SessionService{db: db}
+}
+
+// CreateSession records a newly issued token and the device it was issued to
+// and returns the session ID. Only the SHA-256 hash of the token ID is stored.
+func (s *SessionService) CreateSession(userID int, tokenID string, expiresAt time.Time, client models.ClientInfo) (int, error) {
+	result, err := s.db.Exec(`
+        INSERT INTO sessions (user_id, token_hash, expires_at, user_agent, ip_address, last_seen_at)
+        VALUES (?, ?, ?, ?, ?, NOW())
+    `, userID, hashToken(tokenID), expiresAt, utils.TruncateString(client.UserAgent, 255), client.IPAddress)
+	if err != nil {
+		return 0, fmt.Errorf("error creating session: %w", err)
+	}
+
+	sessionID, err := result.LastInsertId()
+	if err != nil {
+		return 0, fmt.Errorf("error getting session ID: %w", err)
+	}
+
+	return int(sessionID), nil
+}
+
+// ValidateSession checks that the session exists, belongs to the user, was
+// issued for the given token ID and has been neither revoked nor expired.
+func (s *SessionService) ValidateSession(sessionID, userID int, tokenID string) error {
+	var id int
+	err := s.db.QueryRow(`
+        SELECT id
+        FROM sessions
+        WHERE id = ? AND user_id = ? AND token_hash = ?
+          AND revoked_at IS NULL AND expires_at > NOW()
+    `, sessionID, userID, hashToken(tokenID)).Scan(&id)
+
+	if err == sql.ErrNoRows {
+		return ErrSessionInvalid
+	}
+	if err != nil {
+		return fmt.Errorf("error validating session: %w", err)
+	}
+
+	return nil
+}
+
+// RotateSessionToken binds the session to a newly issued access token,
+// extends its expiry and records the device that refreshed it. Access tokens
+// are short-lived, so this keeps the last seen time reasonably current
+// without a write on every request.
+func (s *SessionService) RotateSessionToken(sessionID int, tokenID string, expiresAt time.Time, client models.ClientInfo) error {
+	_, err := s.db.Exec(`
+        UPDATE sessions
+        SET token_hash = ?, expires_at = ?, user_agent = ?, ip_address = ?, last_seen_at = NOW()
+        WHERE id = ? AND revoked_at IS NULL
+    `, hashToken(tokenID), expiresAt, utils.TruncateString(client.UserAgent, 255), client.IPAddress, sessionID)
+	if err != nil {
+		return fmt.Errorf("error rotating session token: %w", err)
+	}
+
+	return nil
+}
+
+// IssueRefreshToken creates a new opaque refresh token in the session's token
+// family. The plain token is returned once; only its hash is stored.
+func (s *SessionService) IssueRefreshToken(sessionID, userID int, expiresAt time.Time) (string, error) {
+	token, err := generateRandomToken(32)
+	if err != nil {
+		return "", err
+	}
+
+	_, err = s.db.Exec(`
+        INSERT INTO refresh_tokens (session_id, user_id, token_hash, expires_at)
+        VALUES (?, ?, ?, ?)
+    `, sessionID, userID, hashToken(token), expiresAt)
+	if err != nil {
+		return "", fmt.Errorf("error creating refresh token: %w", err)
+	}
+
+	return token, nil
+}
+
+// ConsumeRefreshToken marks a refresh token as used and returns the session and
+// user it belongs to. Presenting a token that was already used revokes the
+// whole session, since that means the token was leaked.
+func (s *SessionService) ConsumeRefreshToken(token string) (int, int, error) {
+	var (
+		id, sessionID, userID int
+		expiresAt             time.Time
+		usedAt                sql.NullTime
+		sessionRevoked        bool
+	)
+	err := s.db.QueryRow(`
+        SELECT rt.id, rt.session_id, rt.user_id, rt.expires_at, rt.used_at,
+               s.revoked_at IS NOT NULL
+        FROM refresh_tokens rt
+        JOIN sessions s ON rt.session_id = s.id
+        WHERE rt.token_hash = ?
+    `, hashToken(token)).Scan(&id, &sessionID, &userID, &expiresAt, &usedAt, &sessionRevoked)
+
+	if err == sql.ErrNoRows {
+		return 0, 0, ErrRefreshTokenInvalid
+	}
+	if err != nil {
+		return 0, 0, fmt.Errorf("error finding refresh token: %w", err)
+	}
+
+	if usedAt.Valid {
+		if err := s.RevokeSession(sessionID, userID); err != nil {
+			return 0, 0, err
+		}
+		return 0, 0, ErrRefreshTokenReused
+	}
+	if sessionRevoked || time.Now().After(expiresAt) {
+		return 0, 0, ErrRefreshTokenInvalid
+	}
+
+	// Guard against two concurrent refreshes with the same token
+	result, err := s.db.Exec(`
+        UPDATE refresh_tokens
+        SET used_at = NOW()
+        WHERE id = ? AND used_at IS NULL
+    `, id)
+	if err != nil {
+		return 0, 0, fmt.Errorf("error consuming refresh token: %w", err)
+	}
+	if affected, err := result.RowsAffected(); err == nil && affected == 0 {
+		if err := s.RevokeSession(sessionID, userID); err != nil {
+			return 0, 0, err
+		}
+		return 0, 0, ErrRefreshTokenReused
+	}
+
+	return sessionID, userID, nil
+}
+
+// RevokeSession revokes a single session belonging to the user.
+func (s *SessionService) RevokeSession(sessionID, userID int) error {
+	_, err := s.db.Exec(`
+        UPDATE sessions
+        SET revoked_at = NOW()
+        WHERE id = ? AND user_id = ? AND revoked_at IS NULL
+    `, sessionID, userID)
+	if err != nil {
+		return fmt.Errorf("error revoking session: %w", err)
+	}
+
+	return nil
+}
+
+// ListSessions returns the user's active sessions, most recently used first.
+// The session making the request is marked as current.
+func (s *SessionService) ListSessions(userID, currentSessionID int) ([]models.Session, error) {
+	rows, err := s.db.Query(`
+        SELECT id, COALESCE(user_agent, ''), COALESCE(ip_address, ''), created_at,
+               COALESCE(last_seen_at, created_at), expires_at
+        FROM sessions
+        WHERE user_id = ? AND revoked_at IS NULL AND expires_at > NOW()
+        ORDER BY COALESCE(last_seen_at, created_at) DESC
+    `, userID)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching sessions: %w", err)
+	}
+	defer rows.Close()
+
+	sessions := []models.Session{}
+	for rows.Next() {
+		var session models.Session
+		err := rows.Scan(
+			&session.ID,
+			&session.UserAgent,
+			&session.IPAddress,
+			&session.CreatedAt,
+			&session.LastSeenAt,
+			&session.ExpiresAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning session: %w", err)
+		}
+		session.Current = session.ID == currentSessionID
+		sessions = append(sessions, session)
+	}
+
+	return sessions, nil
+}
+
+// RevokeActiveSession signs out one of the user's active sessions.
+func (s *SessionService) RevokeActiveSession(sessionID, userID int) error {
+	result, err := s.db.Exec(`
+        UPDATE sessions
+        SET revoked_at = NOW()
+        WHERE id = ? AND user_id = ? AND revoked_at IS NULL AND expires_at > NOW()
+    `, sessionID, userID)
+	if err != nil {
+		return fmt.Errorf("error revoking session: %w", err)
+	}
+	if affected, err := result.RowsAffected(); err == nil && affected == 0 {
+		return ErrSessionNotFound
+	}
+
+	return nil
+}
+
+// RevokeAllSessions revokes every active session of the user.
+func (s *SessionService) RevokeAllSessions(userID int) error {
+	_, err := s.db.Exec(`
+        UPDATE sessions
+        SET revoked_at = NOW()
+        WHERE user_id = ? AND revoked_at IS NULL
+    `, userID)
+	if err != nil {
+		return fmt.Errorf("error revoking sessions: %w", err)
+	}
+
+	return nil
+}
+
+// RevokeOtherSessions revokes every active session of the user except the given one.
+func (s *SessionService) RevokeOtherSessions(userID, keepSessionID int) error {
+	_, err := s.db.Exec(`
+        UPDATE sessions
+        SET revoked_at = NOW()
+        WHERE user_id = ? AND id != ? AND revoked_at IS NULL
+    `, userID, keepSessionID)
+	if err != nil {
+		return fmt.Errorf("error revoking sessions: %w", err)
+	}
+
+	return nil
+}
+
+// Helper functions
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+func generateRandomToken(size int) (string, error) {
+	bytes := make([]byte, size)
+	if _, err := rand.Read(bytes); err != nil {
+		return "", fmt.Errorf("error generating random token: %w", err)
+	}
+	return hex.EncodeToString(bytes), nil
+}
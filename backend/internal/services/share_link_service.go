@@ -0,0 +1,201 @@
+package services
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"backend/internal/models"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+var (
+	// ErrShareLinkInvalid is returned for unknown, expired or revoked links
+	// and for links whose project is no longer available.
+	ErrShareLinkInvalid = errors.New("share link is invalid, expired or revoked")
+	// ErrShareLinkNotFound is returned when revoking a link of another project.
+	ErrShareLinkNotFound = errors.New("share link not found")
+	// ErrShareLinkPasswordRequired is returned when a protected link is
+	// opened without a password.
+	ErrShareLinkPasswordRequired = errors.New("this link is password protected")
+	// ErrShareLinkPasswordInvalid is returned for a wrong link password.
+	ErrShareLinkPasswordInvalid = errors.New("incorrect link password")
+)
+
+// ShareLinkService manages secret read-only links to projects, for sharing
+// a private project with readers who have no account.
+type ShareLinkService struct {
+	db       *sql.DB
+	projects *ProjectService
+}
+
+func NewShareLinkService(db *sql.DB, projectService *ProjectService) *ShareLinkService {
+	return &ShareLinkService{
+		db:       db,
+		projects: projectService,
+	}
+}
+
+// CreateLink creates a share link. Only owners can share a project. The plain
+// token is only returned here.
+func (s *ShareLinkService) CreateLink(projectID, userID int, req models.CreateShareLinkRequest) (*models.CreateShareLinkResponse, error) {
+	if _, err := s.projects.requireProjectRole(projectID, userID, models.ProjectRoleOwner); err != nil {
+		return nil, err
+	}
+
+	token, err := generateRandomToken(24)
+	if err != nil {
+		return nil, err
+	}
+
+	var passwordHash interface{}
+	if req.Password != "" {
+		hashed, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
+		if err != nil {
+			return nil, fmt.Errorf("error hashing password: %w", err)
+		}
+		passwordHash = string(hashed)
+	}
+
+	var expiresAt *time.Time
+	if req.ExpiresInDays != nil {
+		expiry := time.Now().AddDate(0, 0, *req.ExpiresInDays)
+		expiresAt = &expiry
+	}
+
+	result, err := s.db.Exec(`
+        INSERT INTO project_share_links (project_id, created_by, label, token_hash, password_hash, expires_at)
+        VALUES (?, ?, ?, ?, ?, ?)
+    `, projectID, userID, req.Label, hashToken(token), passwordHash, expiresAt)
+	if err != nil {
+		return nil, fmt.Errorf("error creating share link: %w", err)
+	}
+
+	linkID, err := result.LastInsertId()
+	if err != nil {
+		return nil, fmt.Errorf("error getting share link ID: %w", err)
+	}
+
+	return &models.CreateShareLinkResponse{
+		ShareLink: models.ShareLink{
+			ID:          int(linkID),
+			Label:       req.Label,
+			HasPassword: req.Password != "",
+			ExpiresAt:   expiresAt,
+			CreatedAt:   time.Now(),
+		},
+		Token: token,
+	}, nil
+}
+
+// ListLinks returns the project's links that have not been revoked, with how
+// often each was opened. Only owners can see them.
+func (s *ShareLinkService) ListLinks(projectID, userID int) ([]models.ShareLink, error) {
+	if _, err := s.projects.requireProjectRole(projectID, userID, models.ProjectRoleOwner); err != nil {
+		return nil, err
+	}
+
+	rows, err := s.db.Query(`
+        SELECT id, label, password_hash IS NOT NULL, expires_at, open_count, last_opened_at, created_at
+        FROM project_share_links
+        WHERE project_id = ? AND revoked_at IS NULL
+        ORDER BY created_at DESC
+    `, projectID)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching share links: %w", err)
+	}
+	defer rows.Close()
+
+	links := []models.ShareLink{}
+	for rows.Next() {
+		var link models.ShareLink
+		err := rows.Scan(
+			&link.ID,
+			&link.Label,
+			&link.HasPassword,
+			&link.ExpiresAt,
+			&link.OpenCount,
+			&link.LastOpenedAt,
+			&link.CreatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning share link: %w", err)
+		}
+		links = append(links, link)
+	}
+
+	return links, nil
+}
+
+// RevokeLink disables a link for good. Only owners can revoke links.
+func (s *ShareLinkService) RevokeLink(projectID, userID, linkID int) error {
+	if _, err := s.projects.requireProjectRole(projectID, userID, models.ProjectRoleOwner); err != nil {
+		return err
+	}
+
+	result, err := s.db.Exec(`
+        UPDATE project_share_links
+        SET revoked_at = NOW()
+        WHERE id = ? AND project_id = ? AND revoked_at IS NULL
+    `, linkID, projectID)
+	if err != nil {
+		return fmt.Errorf("error revoking share link: %w", err)
+	}
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		return ErrShareLinkNotFound
+	}
+
+	return nil
+}
+
+// OpenLink returns the project behind a share link and counts the visit.
+// Projects taken down by a moderator cannot be opened through links either.
+func (s *ShareLinkService) OpenLink(token, password string) (*models.Project, error) {
+	var (
+		linkID       int
+		projectID    int
+		passwordHash sql.NullString
+		expiresAt    sql.NullTime
+	)
+	err := s.db.QueryRow(`
+        SELECT id, project_id, password_hash, expires_at
+        FROM project_share_links
+        WHERE token_hash = ? AND revoked_at IS NULL
+    `, hashToken(token)).Scan(&linkID, &projectID, &passwordHash, &expiresAt)
+	if err == sql.ErrNoRows {
+		return nil, ErrShareLinkInvalid
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error finding share link: %w", err)
+	}
+	if expiresAt.Valid && time.Now().After(expiresAt.Time) {
+		return nil, ErrShareLinkInvalid
+	}
+
+	if passwordHash.Valid {
+		if password == "" {
+			return nil, ErrShareLinkPasswordRequired
+		}
+		if err := bcrypt.CompareHashAndPassword([]byte(passwordHash.String), []byte(password)); err != nil {
+			return nil, ErrShareLinkPasswordInvalid
+		}
+	}
+
+	project := s.projects.getSharedProject(projectID)
+	if project == nil {
+		return nil, ErrShareLinkInvalid
+	}
+
+	_, err = s.db.Exec(`
+        UPDATE project_share_links
+        SET open_count = open_count + 1, last_opened_at = NOW()
+        WHERE id = ?
+    `, linkID)
+	if err != nil {
+		return nil, fmt.Errorf("error recording share link visit: %w", err)
+	}
+
+	return project, nil
+}
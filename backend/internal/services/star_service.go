@@ -0,0 +1,126 @@
+package services
+
+import (
+	"database/sql"
+	"fmt"
+
+	"backend/internal/models"
+)
+
+// starCountColumn counts the stars of p. It is the popularity of a project.
+const starCountColumn = "(SELECT COUNT(*) FROM project_stars ps WHERE ps.project_id = p.id)"
+
+// Featured ranking. A project's score is its stars and recent views divided
+// by a power of its age in hours, like news aggregators rank links, so new
+// projects can climb quickly and old ones sink unless they stay popular.
+// Editor picks are ranked above everything else.
+const (
+	featuredStarWeight = 3.0
+	featuredViewWeight = 0.1
+	featuredViewDays   = 30
+	featuredGravity    = 1.5
+	editorPickBoost    = 1000000
+)
+
+// featuredScoreColumn scores p for the featured ranking. It takes the time
+// the ranking is computed at twice, as arguments.
+var featuredScoreColumn = fmt.Sprintf(`(CASE WHEN p.picked_at IS NOT NULL THEN %d ELSE 0 END +
+        (1 + %s * %g + COALESCE((SELECT SUM(v.views) FROM project_views v WHERE v.project_id = p.id AND v.day >= DATE_SUB(?, INTERVAL %d DAY)), 0) * %g)
+        / POW(GREATEST(TIMESTAMPDIFF(HOUR, p.created_at, ?), 0) + 2, %g))`,
+	editorPickBoost, starCountColumn, featuredStarWeight, featuredViewDays, featuredViewWeight, featuredGravity)
+
+// StarService manages the stars users give to projects.
+type StarService struct {
+	db       *sql.DB
+	projects *ProjectService
+}
+
+func NewStarService(db *sql.DB, projectService *ProjectService) *StarService {
+	return &StarService{
+		db:       db,
+		projects: projectService,
+	}
+}
+
+// Star stars a project the user can see. Starring twice has no effect.
+func (s *StarService) Star(projectID, userID int) (*models.StarStatus, error) {
+	if err := s.checkVisible(projectID, userID); err != nil {
+		return nil, err
+	}
+
+	_, err := s.db.Exec(`
+        INSERT IGNORE INTO project_stars (user_id, project_id) VALUES (?, ?)
+    `, userID, projectID)
+	if err != nil {
+		return nil, fmt.Errorf("error starring project: %w", err)
+	}
+
+	return s.status(projectID, userID)
+}
+
+// Unstar removes the user's star from a project.
+func (s *StarService) Unstar(projectID, userID int) (*models.StarStatus, error) {
+	_, err := s.db.Exec("DELETE FROM project_stars WHERE user_id = ? AND project_id = ?", userID, projectID)
+	if err != nil {
+		return nil, fmt.Errorf("error unstarring project: %w", err)
+	}
+
+	return s.status(projectID, userID)
+}
+
+// GetStarredProjects returns a page of the projects the user starred that
+// they can still see.
+func (s *StarService) GetStarredProjects(userID int, filter models.ProjectFilter) (*models.ProjectPage, error) {
+	listing, err := newProjectListing(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	condition, args := discoveryFilter(filter)
+	args = append([]interface{}{userID, userID, userID}, args...)
+	pageCondition, pageArgs, err := listing.condition(filter)
+	if err != nil {
+		return nil, err
+	}
+	orderBy, orderArgs := listing.orderBy()
+	args = append(append(args, pageArgs...), orderArgs...)
+
+	rows, err := s.db.Query(`
+        SELECT `+projectListColumns+`
+        FROM projects p
+        JOIN users u ON p.user_id = u.id
+        WHERE p.id IN (SELECT project_id FROM project_stars WHERE user_id = ?)
+          AND (p.user_id = ? OR p.id IN (SELECT project_id FROM project_members WHERE user_id = ?)
+               OR (p.visibility <> 'private'`+s.projects.publishedFilter()+`))
+          AND p.deleted_at IS NULL`+condition+pageCondition+orderBy, args...)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching starred projects: %w", err)
+	}
+
+	projects, err := s.projects.scanProjectList(rows)
+	if err != nil {
+		return nil, err
+	}
+	return listing.page(projects), nil
+}
+
+// checkVisible allows starring public and unlisted projects, and private
+// ones the user is a member of.
+func (s *StarService) checkVisible(projectID, userID int) error {
+	if s.projects.GetPublicProjectByID(projectID) != nil {
+		return nil
+	}
+	_, err := s.projects.ProjectRole(projectID, userID)
+	return err
+}
+
+func (s *StarService) status(projectID, userID int) (*models.StarStatus, error) {
+	var status models.StarStatus
+	err := s.db.QueryRow(`
+        SELECT COUNT(*), COALESCE(SUM(user_id = ?), 0) > 0 FROM project_stars WHERE project_id = ?
+    `, userID, projectID).Scan(&status.StarCount, &status.Starred)
+	if err != nil {
+		return nil, fmt.Errorf("error counting stars: %w", err)
+	}
+	return &status, nil
+}
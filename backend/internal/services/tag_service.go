@@ -0,0 +1,175 @@
+package services
+
+import (
+	"database/sql"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"backend/internal/models"
+)
+
+const (
+	maxTagLength        = 30
+	defaultTagCloudSize = 50
+)
+
+// tagSeparator matches everything but letters, combining marks and digits in
+// any script, so tags like "ภาษาไทย" survive normalisation
+var tagSeparator = regexp.MustCompile(`[^\p{L}\p{M}\p{N}]+`)
+
+// TagService manages the free-form tags of projects and the tag cloud shown
+// when browsing.
+type TagService struct {
+	db       *sql.DB
+	projects *ProjectService
+}
+
+func NewTagService(db *sql.DB, projectService *ProjectService) *TagService {
+	return &TagService{
+		db:       db,
+		projects: projectService,
+	}
+}
+
+// SetTags replaces the tags of a project the user can edit and returns the
+// stored, normalised tags.
+func (s *TagService) SetTags(projectID, userID int, tags []string) ([]string, error) {
+	if _, err := s.projects.requireProjectRole(projectID, userID, models.ProjectRoleEditor); err != nil {
+		return nil, err
+	}
+
+	normalized := []string{}
+	seen := map[string]bool{}
+	for _, tag := range tags {
+		tag = normalizeTag(tag)
+		if tag == "" || seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		normalized = append(normalized, tag)
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("error starting transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec("DELETE FROM project_tags WHERE project_id = ?", projectID); err != nil {
+		return nil, fmt.Errorf("error clearing tags: %w", err)
+	}
+	// The column's collation treats e.g. "épée" and "epee" as the same tag;
+	// only the first one given is kept
+	stored := []string{}
+	for _, tag := range normalized {
+		result, err := tx.Exec("INSERT IGNORE INTO project_tags (project_id, tag) VALUES (?, ?)", projectID, tag)
+		if err != nil {
+			return nil, fmt.Errorf("error adding tag: %w", err)
+		}
+		if affected, err := result.RowsAffected(); err == nil && affected > 0 {
+			stored = append(stored, tag)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("error saving tags: %w", err)
+	}
+	return stored, nil
+}
+
+// RemoveTag removes one tag from a project the user can edit.
+func (s *TagService) RemoveTag(projectID, userID int, tag string) error {
+	if _, err := s.projects.requireProjectRole(projectID, userID, models.ProjectRoleEditor); err != nil {
+		return err
+	}
+
+	_, err := s.db.Exec("DELETE FROM project_tags WHERE project_id = ? AND tag = ?", projectID, normalizeTag(tag))
+	if err != nil {
+		return fmt.Errorf("error removing tag: %w", err)
+	}
+	return nil
+}
+
+// TagCloud returns the most used tags among public projects with how many
+// projects use each.
+func (s *TagService) TagCloud(limit int) ([]models.TagCount, error) {
+	if limit <= 0 {
+		limit = defaultTagCloudSize
+	}
+
+	rows, err := s.db.Query(`
+        SELECT t.tag, COUNT(*) AS uses
+        FROM project_tags t
+        JOIN projects p ON t.project_id = p.id
+        JOIN users u ON p.user_id = u.id
+        WHERE 1 = 1`+s.projects.listedFilter()+`
+        GROUP BY t.tag
+        ORDER BY uses DESC, t.tag
+        LIMIT ?
+    `, limit)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching tags: %w", err)
+	}
+	defer rows.Close()
+
+	tags := []models.TagCount{}
+	for rows.Next() {
+		var tag models.TagCount
+		if err := rows.Scan(&tag.Tag, &tag.Count); err != nil {
+			return nil, fmt.Errorf("error scanning tag: %w", err)
+		}
+		tags = append(tags, tag)
+	}
+
+	return tags, nil
+}
+
+// normalizeTag turns free-form input into the stored form, e.g.
+// "Court Intrigue" becomes "court-intrigue". Length is counted in characters,
+// like the VARCHAR column.
+func normalizeTag(tag string) string {
+	tag = strings.Trim(tagSeparator.ReplaceAllString(strings.ToLower(tag), "-"), "-")
+	if runes := []rune(tag); len(runes) > maxTagLength {
+		tag = strings.Trim(string(runes[:maxTagLength]), "-")
+	}
+	return tag
+}
+
+// projectTags returns the tags of the given projects, keyed by project ID.
+func projectTags(db *sql.DB, projectIDs []int) (map[int][]string, error) {
+	tags := make(map[int][]string, len(projectIDs))
+	for _, id := range projectIDs {
+		tags[id] = []string{}
+	}
+	if len(projectIDs) == 0 {
+		return tags, nil
+	}
+
+	placeholders := strings.TrimSuffix(strings.Repeat("?,", len(projectIDs)), ",")
+	args := make([]interface{}, len(projectIDs))
+	for i, id := range projectIDs {
+		args[i] = id
+	}
+
+	rows, err := db.Query(`
+        SELECT project_id, tag FROM project_tags WHERE project_id IN (`+placeholders+`) ORDER BY tag
+    `, args...)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching tags: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			projectID int
+			tag       string
+		)
+		if err := rows.Scan(&projectID, &tag); err != nil {
+			return nil, fmt.Errorf("error scanning tag: %w", err)
+		}
+		tags[projectID] = append(tags[projectID], tag)
+	}
+
+	return tags, nil
+}
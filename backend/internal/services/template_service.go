@@ -0,0 +1,208 @@
+package services
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"backend/internal/models"
+	"backend/pkg/utils"
+)
+
+// ErrTemplateNotFound is returned for unknown templates and for templates
+// saved by other users.
+var ErrTemplateNotFound = errors.New("template not found")
+
+// TemplateService serves the built-in project templates and the templates
+// users save from their own projects.
+type TemplateService struct {
+	db       *sql.DB
+	projects *ProjectService
+}
+
+func NewTemplateService(db *sql.DB, projectService *ProjectService) *TemplateService {
+	return &TemplateService{
+		db:       db,
+		projects: projectService,
+	}
+}
+
+// ListTemplates returns the built-in templates followed by the user's own,
+// without their diagrams. Anonymous callers (userID 0) only get built-ins.
+func (s *TemplateService) ListTemplates(userID int) ([]models.ProjectTemplate, error) {
+	templates := []models.ProjectTemplate{}
+	for _, builtin := range builtinTemplates {
+		templates = append(templates, builtin.summary())
+	}
+
+	if userID == 0 {
+		return templates, nil
+	}
+
+	rows, err := s.db.Query(`
+        SELECT id, name, description, project_data, created_at
+        FROM project_templates
+        WHERE user_id = ?
+        ORDER BY created_at DESC
+    `, userID)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching templates: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		template, err := scanTemplate(rows)
+		if err != nil {
+			return nil, err
+		}
+		template.ProjectData = nil
+		templates = append(templates, *template)
+	}
+
+	return templates, nil
+}
+
+// GetTemplate returns a built-in template or one of the user's templates,
+// including its diagram.
+func (s *TemplateService) GetTemplate(templateID string, userID int) (*models.ProjectTemplate, error) {
+	return findTemplate(s.db, templateID, userID)
+}
+
+func findTemplate(db *sql.DB, templateID string, userID int) (*models.ProjectTemplate, error) {
+	for _, builtin := range builtinTemplates {
+		if builtin.id == templateID {
+			template := builtin.summary()
+			data, err := builtin.projectData()
+			if err != nil {
+				return nil, err
+			}
+			template.ProjectData = data
+			return &template, nil
+		}
+	}
+
+	id, err := strconv.Atoi(templateID)
+	if err != nil || userID == 0 {
+		return nil, ErrTemplateNotFound
+	}
+
+	template, err := scanTemplate(db.QueryRow(`
+        SELECT id, name, description, project_data, created_at
+        FROM project_templates
+        WHERE id = ? AND user_id = ?
+    `, id, userID))
+	if err == sql.ErrNoRows {
+		return nil, ErrTemplateNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return template, nil
+}
+
+// SaveTemplate saves a copy of a project's diagram as a template of the
+// user. Like forking, owners can save their own projects and anyone can save
+// a public project.
+func (s *TemplateService) SaveTemplate(projectID, userID int, req models.SaveTemplateRequest) (*models.ProjectTemplate, error) {
+	project, err := s.projects.copyProject(projectID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	name := utils.SanitizeString(req.Name)
+	description := utils.SanitizeString(req.Description)
+
+	result, err := s.db.Exec(`
+        INSERT INTO project_templates (user_id, name, description, project_data)
+        VALUES (?, ?, ?, ?)
+    `, userID, name, description, project.ProjectData)
+	if err != nil {
+		return nil, fmt.Errorf("error saving template: %w", err)
+	}
+
+	templateID, err := result.LastInsertId()
+	if err != nil {
+		return nil, fmt.Errorf("error getting template ID: %w", err)
+	}
+
+	return s.GetTemplate(strconv.FormatInt(templateID, 10), userID)
+}
+
+// DeleteTemplate deletes one of the user's templates. Built-ins cannot be deleted.
+func (s *TemplateService) DeleteTemplate(templateID string, userID int) error {
+	id, err := strconv.Atoi(templateID)
+	if err != nil {
+		return ErrTemplateNotFound
+	}
+
+	result, err := s.db.Exec("DELETE FROM project_templates WHERE id = ? AND user_id = ?", id, userID)
+	if err != nil {
+		return fmt.Errorf("error deleting template: %w", err)
+	}
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		return ErrTemplateNotFound
+	}
+
+	return nil
+}
+
+// templateProjectData returns the diagram a new project starts from, with
+// fresh element IDs so projects made from one template never share IDs.
+func templateProjectData(db *sql.DB, templateID string, userID int) (json.RawMessage, error) {
+	template, err := findTemplate(db, templateID, userID)
+	if err != nil {
+		return nil, err
+	}
+	return regenerateElementIDs(template.ProjectData)
+}
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanTemplate(row rowScanner) (*models.ProjectTemplate, error) {
+	var (
+		id          int
+		description sql.NullString
+		createdAt   time.Time
+		template    models.ProjectTemplate
+	)
+	err := row.Scan(&id, &template.Name, &description, &template.ProjectData, &createdAt)
+	if err == sql.ErrNoRows {
+		return nil, err
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error scanning template: %w", err)
+	}
+
+	template.ID = strconv.Itoa(id)
+	template.Description = description.String
+	template.CreatedAt = &createdAt
+	template.CharacterCount, template.RelationshipCount = countElements(template.ProjectData)
+
+	return &template, nil
+}
+
+// countElements counts the characters and relationships of a diagram.
+// Diagrams that cannot be parsed count as empty.
+func countElements(projectData json.RawMessage) (int, int) {
+	var data models.ProjectData
+	if err := json.Unmarshal(projectData, &data); err != nil {
+		return 0, 0
+	}
+
+	characters, relationships := 0, 0
+	for _, element := range data.Elements {
+		switch element.Type {
+		case elementCharacter:
+			characters++
+		case elementRelationship:
+			relationships++
+		}
+	}
+	return characters, relationships
+}
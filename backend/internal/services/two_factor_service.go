@@ -0,0 +1,275 @@
+package services
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"backend/internal/config"
+	"backend/internal/models"
+	"backend/pkg/totp"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+const recoveryCodeCount = 10
+
+var (
+	// ErrTwoFactorAlreadyEnabled is returned when enrolling a user who already uses 2FA.
+	ErrTwoFactorAlreadyEnabled = errors.New("two-factor authentication is already enabled")
+	// ErrTwoFactorNotEnabled is returned for 2FA operations on users without 2FA.
+	ErrTwoFactorNotEnabled = errors.New("two-factor authentication is not enabled")
+	// ErrTwoFactorNotPending is returned when confirming without starting setup first.
+	ErrTwoFactorNotPending = errors.New("two-factor setup has not been started")
+	// ErrInvalidTwoFactorCode is returned for wrong, reused or expired codes.
+	ErrInvalidTwoFactorCode = errors.New("invalid two-factor code")
+)
+
+// TwoFactorService manages TOTP enrolment, verification and recovery codes.
+type TwoFactorService struct {
+	db     *sql.DB
+	config *config.Config
+}
+
+func NewTwoFactorService(db *sql.DB, cfg *config.Config) *TwoFactorService {
+	return &TwoFactorService{
+		db:     db,
+		config: cfg,
+	}
+}
+
+// IsEnabled reports whether the user has confirmed 2FA enrolment.
+func (s *TwoFactorService) IsEnabled(userID int) (bool, error) {
+	var enabled bool
+	err := s.db.QueryRow(`
+        SELECT totp_enabled_at IS NOT NULL
+        FROM users
+        WHERE id = ?
+    `, userID).Scan(&enabled)
+	if err != nil {
+		return false, fmt.Errorf("error checking two-factor status: %w", err)
+	}
+
+	return enabled, nil
+}
+
+// Setup generates a new pending TOTP secret. 2FA is not enforced until the
+// user proves they stored it by confirming a code.
+func (s *TwoFactorService) Setup(userID int) (*models.TwoFactorSetupResponse, error) {
+	var (
+		email     string
+		enabledAt sql.NullTime
+	)
+	err := s.db.QueryRow(`
+        SELECT email, totp_enabled_at
+        FROM users
+        WHERE id = ?
+    `, userID).Scan(&email, &enabledAt)
+	if err == sql.ErrNoRows {
+		return nil, errors.New("user not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error finding user: %w", err)
+	}
+	if enabledAt.Valid {
+		return nil, ErrTwoFactorAlreadyEnabled
+	}
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = s.db.Exec(`
+        UPDATE users
+        SET totp_secret = ?, totp_last_step = 0
+        WHERE id = ?
+    `, secret, userID)
+	if err != nil {
+		return nil, fmt.Errorf("error saving two-factor secret: %w", err)
+	}
+
+	return &models.TwoFactorSetupResponse{
+		Secret:     secret,
+		OTPAuthURI: totp.URI(s.config.Auth.TOTPIssuer, email, secret),
+	}, nil
+}
+
+// Confirm enables 2FA once the user submits a valid code for the pending
+// secret, and returns a fresh set of recovery codes.
+func (s *TwoFactorService) Confirm(userID int, code string) ([]string, error) {
+	var (
+		secret    sql.NullString
+		lastStep  int64
+		enabledAt sql.NullTime
+	)
+	err := s.db.QueryRow(`
+        SELECT totp_secret, totp_last_step, totp_enabled_at
+        FROM users
+        WHERE id = ?
+    `, userID).Scan(&secret, &lastStep, &enabledAt)
+	if err == sql.ErrNoRows {
+		return nil, errors.New("user not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error finding user: %w", err)
+	}
+	if enabledAt.Valid {
+		return nil, ErrTwoFactorAlreadyEnabled
+	}
+	if !secret.Valid || secret.String == "" {
+		return nil, ErrTwoFactorNotPending
+	}
+
+	step, ok := totp.Validate(secret.String, code, time.Now(), 1)
+	if !ok || step <= lastStep {
+		return nil, ErrInvalidTwoFactorCode
+	}
+
+	_, err = s.db.Exec(`
+        UPDATE users
+        SET totp_enabled_at = NOW(), totp_last_step = ?
+        WHERE id = ?
+    `, step, userID)
+	if err != nil {
+		return nil, fmt.Errorf("error enabling two-factor authentication: %w", err)
+	}
+
+	return s.RegenerateRecoveryCodes(userID)
+}
+
+// Disable turns 2FA off after checking the password and a current TOTP or
+// recovery code. Accounts without a password only need the code.
+func (s *TwoFactorService) Disable(userID int, req models.DisableTwoFactorRequest) error {
+	var passwordHash string
+	err := s.db.QueryRow("SELECT password_hash FROM users WHERE id = ?", userID).Scan(&passwordHash)
+	if err == sql.ErrNoRows {
+		return errors.New("user not found")
+	}
+	if err != nil {
+		return fmt.Errorf("error finding user: %w", err)
+	}
+
+	if passwordHash != "" {
+		if err := bcrypt.CompareHashAndPassword([]byte(passwordHash), []byte(req.Password)); err != nil {
+			return ErrInvalidPassword
+		}
+	}
+
+	if err := s.Verify(userID, req.Code, req.RecoveryCode); err != nil {
+		return err
+	}
+
+	_, err = s.db.Exec(`
+        UPDATE users
+        SET totp_secret = NULL, totp_enabled_at = NULL, totp_last_step = 0
+        WHERE id = ?
+    `, userID)
+	if err != nil {
+		return fmt.Errorf("error disabling two-factor authentication: %w", err)
+	}
+
+	_, err = s.db.Exec("DELETE FROM recovery_codes WHERE user_id = ?", userID)
+	if err != nil {
+		return fmt.Errorf("error deleting recovery codes: %w", err)
+	}
+
+	return nil
+}
+
+// Verify checks either a TOTP code or a one-time recovery code for a user
+// with 2FA enabled. Each TOTP code and recovery code is accepted only once.
+func (s *TwoFactorService) Verify(userID int, code, recoveryCode string) error {
+	if recoveryCode != "" {
+		return s.consumeRecoveryCode(userID, recoveryCode)
+	}
+
+	var (
+		secret    sql.NullString
+		lastStep  int64
+		enabledAt sql.NullTime
+	)
+	err := s.db.QueryRow(`
+        SELECT totp_secret, totp_last_step, totp_enabled_at
+        FROM users
+        WHERE id = ?
+    `, userID).Scan(&secret, &lastStep, &enabledAt)
+	if err == sql.ErrNoRows {
+		return errors.New("user not found")
+	}
+	if err != nil {
+		return fmt.Errorf("error finding user: %w", err)
+	}
+	if !enabledAt.Valid || !secret.Valid {
+		return ErrTwoFactorNotEnabled
+	}
+
+	step, ok := totp.Validate(secret.String, code, time.Now(), 1)
+	if !ok || step <= lastStep {
+		return ErrInvalidTwoFactorCode
+	}
+
+	// Record the step so the same code cannot be replayed
+	result, err := s.db.Exec(`
+        UPDATE users
+        SET totp_last_step = ?
+        WHERE id = ? AND totp_last_step < ?
+    `, step, userID, step)
+	if err != nil {
+		return fmt.Errorf("error recording two-factor code: %w", err)
+	}
+	if affected, err := result.RowsAffected(); err == nil && affected == 0 {
+		return ErrInvalidTwoFactorCode
+	}
+
+	return nil
+}
+
+// RegenerateRecoveryCodes replaces all recovery codes of the user. The plain
+// codes are returned once; only their hashes are stored.
+func (s *TwoFactorService) RegenerateRecoveryCodes(userID int) ([]string, error) {
+	_, err := s.db.Exec("DELETE FROM recovery_codes WHERE user_id = ?", userID)
+	if err != nil {
+		return nil, fmt.Errorf("error deleting recovery codes: %w", err)
+	}
+
+	codes := make([]string, 0, recoveryCodeCount)
+	for i := 0; i < recoveryCodeCount; i++ {
+		raw, err := generateRandomToken(5)
+		if err != nil {
+			return nil, err
+		}
+		code := raw[:5] + "-" + raw[5:]
+
+		_, err = s.db.Exec(`
+            INSERT INTO recovery_codes (user_id, code_hash)
+            VALUES (?, ?)
+        `, userID, hashToken(code))
+		if err != nil {
+			return nil, fmt.Errorf("error saving recovery code: %w", err)
+		}
+		codes = append(codes, code)
+	}
+
+	return codes, nil
+}
+
+func (s *TwoFactorService) consumeRecoveryCode(userID int, code string) error {
+	code = strings.ToLower(strings.TrimSpace(code))
+
+	result, err := s.db.Exec(`
+        UPDATE recovery_codes
+        SET used_at = NOW()
+        WHERE user_id = ? AND code_hash = ? AND used_at IS NULL
+    `, userID, hashToken(code))
+	if err != nil {
+		return fmt.Errorf("error using recovery code: %w", err)
+	}
+	if affected, err := result.RowsAffected(); err == nil && affected == 0 {
+		return ErrInvalidTwoFactorCode
+	}
+
+	return nil
+}
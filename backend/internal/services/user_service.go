@@ -0,0 +1,131 @@
+package services
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"backend/internal/models"
+	"backend/pkg/utils"
+)
+
+const (
+	minUsernameLength = 2
+	maxUsernameLength = 30
+)
+
+var (
+	// ErrUserNotFound is returned for usernames that do not exist.
+	ErrUserNotFound = errors.New("user not found")
+	// ErrUsernameTaken is returned when the requested username belongs to someone else.
+	ErrUsernameTaken = errors.New("username is already taken")
+	// ErrInvalidUsername is returned for usernames that are not URL-safe.
+	ErrInvalidUsername = fmt.Errorf("username must be %d-%d lowercase letters, digits or single hyphens", minUsernameLength, maxUsernameLength)
+)
+
+// UserService serves public author profiles.
+type UserService struct {
+	db       *sql.DB
+	projects *ProjectService
+}
+
+func NewUserService(db *sql.DB, projectService *ProjectService) *UserService {
+	return &UserService{
+		db:       db,
+		projects: projectService,
+	}
+}
+
+// GetPublicProfile returns an author's public details and published projects.
+// The email address is never included.
+func (s *UserService) GetPublicProfile(username string) (*models.PublicProfile, error) {
+	var (
+		userID  int
+		profile models.PublicProfile
+	)
+	err := s.db.QueryRow(`
+        SELECT id, username, user_name, bio, profile_image, created_at
+        FROM users
+        WHERE username = ?
+    `, strings.ToLower(username)).Scan(
+		&userID,
+		&profile.Username,
+		&profile.UserName,
+		&profile.Bio,
+		&profile.ProfileImage,
+		&profile.CreatedAt,
+	)
+	if err == sql.ErrNoRows {
+		return nil, ErrUserNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error finding user: %w", err)
+	}
+
+	profile.Projects, err = s.projects.GetPublicProjectsByUser(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &profile, nil
+}
+
+// ValidateUsername checks that a username is already in the URL-safe form
+// produced by utils.SlugifyString.
+func ValidateUsername(username string) error {
+	if len(username) < minUsernameLength || len(username) > maxUsernameLength || utils.SlugifyString(username) != username {
+		return ErrInvalidUsername
+	}
+	return nil
+}
+
+// usernameTaken reports whether another user already has the username.
+func usernameTaken(db *sql.DB, username string, exceptUserID int) (bool, error) {
+	var id int
+	err := db.QueryRow("SELECT id FROM users WHERE username = ? AND id != ?", username, exceptUserID).Scan(&id)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("error checking username: %w", err)
+	}
+	return true, nil
+}
+
+// uniqueUsername derives a free username from the display name, falling back
+// to the email's local part for names without latin letters or digits, and
+// appends -2, -3, ... until it is unused.
+func uniqueUsername(db *sql.DB, displayName, email string) (string, error) {
+	base := utils.SlugifyString(displayName)
+	if len(base) < minUsernameLength {
+		base = utils.SlugifyString(strings.Split(email, "@")[0])
+	}
+	if len(base) < minUsernameLength {
+		base = "user"
+	}
+	// Leave room for a numeric suffix
+	if len(base) > maxUsernameLength-5 {
+		base = strings.Trim(base[:maxUsernameLength-5], "-")
+	}
+
+	candidate := base
+	for suffix := 2; ; suffix++ {
+		taken, err := usernameTaken(db, candidate, 0)
+		if err != nil {
+			return "", err
+		}
+		if !taken {
+			return candidate, nil
+		}
+		if suffix > 100 {
+			random, err := generateRandomToken(2)
+			if err != nil {
+				return "", err
+			}
+			candidate = base + "-" + random
+			continue
+		}
+		candidate = fmt.Sprintf("%s-%d", base, suffix)
+	}
+}
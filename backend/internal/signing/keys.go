@@ -0,0 +1,199 @@
+package signing
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+
+	"backend/internal/config"
+	"backend/pkg/jwk"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Supported values for JWT_ALGORITHM
+const (
+	AlgorithmHS256 = "HS256"
+	AlgorithmRS256 = "RS256"
+	AlgorithmEdDSA = "EdDSA"
+)
+
+type verificationKey struct {
+	alg string
+	key interface{}
+}
+
+// KeySet signs access tokens with the current key and verifies them against
+// every configured key, looked up by the token's kid header. Keeping the
+// previous public key configured for a while after switching the signing key
+// lets tokens issued before the rotation expire normally.
+type KeySet struct {
+	method     jwt.SigningMethod
+	signingKey interface{}
+	kid        string
+	verifyKeys map[string]verificationKey
+	jwks       jwk.Set
+}
+
+// Load builds the key set described by the JWT configuration. With HS256 the
+// shared secret is used and nothing is published in the JWKS.
+func Load(cfg config.JWTConfig) (*KeySet, error) {
+	keys := &KeySet{
+		verifyKeys: make(map[string]verificationKey),
+		jwks:       jwk.Set{Keys: []jwk.Key{}},
+	}
+
+	switch cfg.Algorithm {
+	case AlgorithmHS256:
+		keys.method = jwt.SigningMethodHS256
+		keys.signingKey = []byte(cfg.Secret)
+		keys.verifyKeys[""] = verificationKey{alg: AlgorithmHS256, key: keys.signingKey}
+		return keys, nil
+	case AlgorithmRS256:
+		keys.method = jwt.SigningMethodRS256
+	case AlgorithmEdDSA:
+		keys.method = jwt.SigningMethodEdDSA
+	default:
+		return nil, fmt.Errorf("unsupported JWT algorithm %q", cfg.Algorithm)
+	}
+
+	if cfg.SigningKeyFile == "" {
+		return nil, fmt.Errorf("JWT_SIGNING_KEY_FILE is required for %s", cfg.Algorithm)
+	}
+	data, err := os.ReadFile(cfg.SigningKeyFile)
+	if err != nil {
+		return nil, fmt.Errorf("error reading signing key: %w", err)
+	}
+	privateKey, err := parsePrivateKey(data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid signing key %s: %w", cfg.SigningKeyFile, err)
+	}
+	if algorithmFor(privateKey.Public()) != cfg.Algorithm {
+		return nil, fmt.Errorf("signing key %s cannot be used with %s", cfg.SigningKeyFile, cfg.Algorithm)
+	}
+	keys.signingKey = privateKey
+
+	kid, err := keys.addVerificationKey(privateKey.Public())
+	if err != nil {
+		return nil, err
+	}
+	keys.kid = kid
+
+	for _, path := range cfg.VerificationKeyFiles {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("error reading verification key: %w", err)
+		}
+		publicKey, err := parsePublicKey(data)
+		if err != nil {
+			return nil, fmt.Errorf("invalid verification key %s: %w", path, err)
+		}
+		if _, err := keys.addVerificationKey(publicKey); err != nil {
+			return nil, fmt.Errorf("invalid verification key %s: %w", path, err)
+		}
+	}
+
+	return keys, nil
+}
+
+// Sign signs the claims with the current signing key
+func (k *KeySet) Sign(claims jwt.Claims) (string, error) {
+	token := jwt.NewWithClaims(k.method, claims)
+	if k.kid != "" {
+		token.Header["kid"] = k.kid
+	}
+	return token.SignedString(k.signingKey)
+}
+
+// Keyfunc returns the verification key for a token, for use with jwt.Parse
+func (k *KeySet) Keyfunc(token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+	key, ok := k.verifyKeys[kid]
+	if !ok {
+		return nil, fmt.Errorf("unknown signing key %q", kid)
+	}
+	if token.Method.Alg() != key.alg {
+		return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+	}
+	return key.key, nil
+}
+
+// JWKS returns the public verification keys. It is empty for HS256.
+func (k *KeySet) JWKS() jwk.Set {
+	return k.jwks
+}
+
+func (k *KeySet) addVerificationKey(publicKey crypto.PublicKey) (string, error) {
+	alg := algorithmFor(publicKey)
+	if alg == "" {
+		return "", fmt.Errorf("unsupported key type %T, use an RSA or Ed25519 key", publicKey)
+	}
+
+	key, err := jwk.NewKey(publicKey, alg)
+	if err != nil {
+		return "", err
+	}
+	if _, exists := k.verifyKeys[key.Kid]; exists {
+		return key.Kid, nil
+	}
+
+	k.verifyKeys[key.Kid] = verificationKey{alg: alg, key: publicKey}
+	k.jwks.Keys = append(k.jwks.Keys, key)
+	return key.Kid, nil
+}
+
+func algorithmFor(publicKey crypto.PublicKey) string {
+	switch publicKey.(type) {
+	case *rsa.PublicKey:
+		return AlgorithmRS256
+	case ed25519.PublicKey:
+		return AlgorithmEdDSA
+	default:
+		return ""
+	}
+}
+
+// parsePrivateKey reads a PEM encoded PKCS#8 or PKCS#1 private key
+func parsePrivateKey(data []byte) (crypto.Signer, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("no PEM data found")
+	}
+
+	if key, err := x509.ParsePKCS8PrivateKey(block.Bytes); err == nil {
+		signer, ok := key.(crypto.Signer)
+		if !ok {
+			return nil, fmt.Errorf("unsupported private key type %T", key)
+		}
+		return signer, nil
+	}
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	return nil, errors.New("unsupported private key format, use PKCS#8 or PKCS#1 PEM")
+}
+
+// parsePublicKey reads a PEM encoded public key. Private keys are accepted
+// too, in which case their public half is used.
+func parsePublicKey(data []byte) (crypto.PublicKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("no PEM data found")
+	}
+
+	if key, err := x509.ParsePKIXPublicKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	if key, err := x509.ParsePKCS1PublicKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	if signer, err := parsePrivateKey(data); err == nil {
+		return signer.Public(), nil
+	}
+	return nil, errors.New("unsupported public key format, use PKIX or PKCS#1 PEM")
+}
@@ -4,6 +4,7 @@ package utils
 import (
     "crypto/rand"
     "encoding/hex"
+    "fmt"
     "regexp"
     "strings"
     "time"
@@ -32,20 +33,52 @@ func SanitizeString(input string) string {
     return cleaned
 }
 
+// SanitizeMarkdown cleans user-written markdown like SanitizeString, and
+// also disarms links and images that point at script or data URLs
+func SanitizeMarkdown(input string) string {
+    cleaned := SanitizeString(input)
+    
+    // Replace javascript:, vbscript: and data: link targets
+    unsafeLinkRegex := regexp.MustCompile(`(?i)\]\(\s*(javascript|vbscript|data):(?:[^()]|\([^()]*\))*\)`)
+    cleaned = unsafeLinkRegex.ReplaceAllString(cleaned, "](#)")
+    unsafeReferenceRegex := regexp.MustCompile(`(?im)^(\s*\[[^\]]+\]:\s*)(javascript|vbscript|data):\S*`)
+    cleaned = unsafeReferenceRegex.ReplaceAllString(cleaned, "${1}#")
+    
+    return cleaned
+}
+
 // ValidateEmail validates email format
 func ValidateEmail(email string) bool {
     emailRegex := regexp.MustCompile(`^[a-zA-Z0-9._%+-]+@[a-zA-Z0-9.-]+\.[a-zA-Z]{2,}$`)
     return emailRegex.MatchString(email)
 }
 
-// ValidatePassword checks password strength
-func ValidatePassword(password string) (bool, string) {
-    if len(password) < 6 {
-        return false, "Password must be at least 6 characters long"
+// PasswordPolicy describes the rules a password must satisfy
+type PasswordPolicy struct {
+    MinLength    int
+    MaxLength    int
+    RequireUpper bool
+    RequireLower bool
+    RequireDigit bool
+}
+
+// DefaultPasswordPolicy requires mixed case and a digit
+var DefaultPasswordPolicy = PasswordPolicy{
+    MinLength:    8,
+    MaxLength:    128,
+    RequireUpper: true,
+    RequireLower: true,
+    RequireDigit: true,
+}
+
+// ValidatePassword checks password strength against the given policy
+func ValidatePassword(password string, policy PasswordPolicy) (bool, string) {
+    if len(password) < policy.MinLength {
+        return false, fmt.Sprintf("Password must be at least %d characters long", policy.MinLength)
     }
     
-    if len(password) > 128 {
-        return false, "Password must be less than 128 characters"
+    if policy.MaxLength > 0 && len(password) > policy.MaxLength {
+        return false, fmt.Sprintf("Password must be less than %d characters", policy.MaxLength)
     }
     
     hasUpper := false
@@ -63,8 +96,19 @@ func ValidatePassword(password string) (bool, string) {
         }
     }
     
-    if !hasUpper || !hasLower || !hasDigit {
-        return false, "Password must contain at least one uppercase letter, one lowercase letter, and one digit"
+    missing := []string{}
+    if policy.RequireUpper && !hasUpper {
+        missing = append(missing, "one uppercase letter")
+    }
+    if policy.RequireLower && !hasLower {
+        missing = append(missing, "one lowercase letter")
+    }
+    if policy.RequireDigit && !hasDigit {
+        missing = append(missing, "one digit")
+    }
+    
+    if len(missing) > 0 {
+        return false, "Password must contain at least " + strings.Join(missing, ", ")
     }
     
     return true, ""
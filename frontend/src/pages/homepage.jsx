@@ -3,11 +3,12 @@ import '../components/styles/homepage.css';
 import { CirclePlus, Search } from 'lucide-react';
 import { useNavigate } from 'react-router-dom';
 import { useAuth } from '../context/AuthContext';
-import { projectAPI } from '../services/api';
+import { projectAPI, fetchAllPages } from '../services/api';
 
 function Homepage() {
   const [userProjects, setUserProjects] = useState([]);
   const [publicProjects, setPublicProjects] = useState([]);
+  const [publicCursor, setPublicCursor] = useState(null);
   const [searchQuery, setSearchQuery] = useState('');
   const [filteredPublicProjects, setFilteredPublicProjects] = useState([]);
   const [isLoading, setIsLoading] = useState(true);
@@ -18,12 +19,12 @@ function Homepage() {
     const fetchProjects = async () => {
       try {
         const publicResponse = await projectAPI.getAllProjects();
-        setPublicProjects(publicResponse.data.data || publicResponse.data);
-        setFilteredPublicProjects(publicResponse.data.data || publicResponse.data);
+        setPublicProjects(publicResponse.data.projects);
+        setFilteredPublicProjects(publicResponse.data.projects);
+        setPublicCursor(publicResponse.data.has_more ? publicResponse.data.next_cursor : null);
 
         if (isLoggedIn) {
-          const userResponse = await projectAPI.getUserProjects();
-          setUserProjects(userResponse.data.data || userResponse.data);
+          setUserProjects(await fetchAllPages(projectAPI.getUserProjects));
         }
       } catch (error) {
         console.error('Error fetching projects:', error);
@@ -47,6 +48,16 @@ function Homepage() {
     }
   }, [searchQuery, publicProjects]);
 
+  const loadMorePublicProjects = async () => {
+    try {
+      const response = await projectAPI.getAllProjects({ cursor: publicCursor });
+      setPublicProjects(projects => [...projects, ...response.data.projects]);
+      setPublicCursor(response.data.has_more ? response.data.next_cursor : null);
+    } catch (error) {
+      console.error('Error fetching projects:', error);
+    }
+  };
+
   const handleCreateClick = () => {
     if (!isLoggedIn) {
       alert("You must login to create a new project.");
@@ -189,6 +200,12 @@ function Homepage() {
           </div>
         )}
       </div>
+
+      {publicCursor && (
+        <div className="load-more">
+          <button onClick={loadMorePublicProjects}>Load more</button>
+        </div>
+      )}
     </div>
   );
 }
@@ -34,7 +34,7 @@ function Register() {
     });
 
     if (result.success) {
-      setSuccess('Registration successful! Please log in.');
+      setSuccess('Check your inbox to finish signing up.');
       setTimeout(() => {
         navigate('/login');
       }, 2000);
@@ -45,11 +45,12 @@ export const authAPI = {
 
 // Project API calls
 export const projectAPI = {
-  // Get all public projects (for homepage stories section)
-  getAllProjects: () => api.get('/projects'),
+  // Get a page of public projects (for homepage stories section);
+  // pass { cursor: next_cursor } for the next page
+  getAllProjects: (params) => api.get('/projects', { params }),
   
-  // Get user's own projects
-  getUserProjects: () => api.get('/projects/my'),
+  // Get a page of the user's own projects
+  getUserProjects: (params) => api.get('/projects/my', { params }),
   
   // Create new project
   createProject: (projectData) => api.post('/projects', projectData),
@@ -70,6 +71,19 @@ export const projectAPI = {
   autoSave: (id, elements) => api.post(`/projects/${id}/autosave`, { elements }),
 };
 
+// List endpoints return { projects, next_cursor, has_more }; this follows
+// next_cursor until every page is loaded
+export const fetchAllPages = async (getPage) => {
+  const projects = [];
+  let cursor;
+  do {
+    const response = await getPage(cursor ? { cursor } : undefined);
+    projects.push(...response.data.projects);
+    cursor = response.data.has_more ? response.data.next_cursor : undefined;
+  } while (cursor);
+  return projects;
+};
+
 // Search API (if you want to implement search)
 export const searchAPI = {
   searchProjects: (query) => api.get(`/projects?search=${encodeURIComponent(query)}`),